@@ -6,23 +6,38 @@ import (
 	"log/slog"
 
 	"s-vitaliy/kubectl-plugin-arcane/internal/app"
-	"s-vitaliy/kubectl-plugin-arcane/internal/client/api/common"
-	v0 "s-vitaliy/kubectl-plugin-arcane/internal/client/api/v0"
 	"s-vitaliy/kubectl-plugin-arcane/internal/commands"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"s-vitaliy/kubectl-plugin-arcane/internal/output"
+	"s-vitaliy/kubectl-plugin-arcane/internal/prompt"
 
 	"github.com/alecthomas/kong"
 	"go.uber.org/dig"
+	"golang.org/x/term"
 )
 
 var CLI struct {
+	Kubeconfig string `help:"Path to the kubeconfig file to use for CLI requests."`
+	Context    string `help:"The name of the kubeconfig context to use."`
+	Namespace  string `short:"n" help:"If present, the namespace scope for this CLI request."`
+	Output     string `short:"o" help:"Output format. One of: table, wide, json, yaml, name, jsonpath=..., go-template=..."`
+
 	Stream commands.StreamCmd `cmd:"" help:"Manage Arcane streams."`
 }
 
 const AppDescription = "A command line tool for managing the Arcane streams."
 
 func main() { // coverage-ignore
-	handler := slog.NewTextHandler(os.Stdout, nil)
+	// Logs go to stderr to keep stdout for the command output.
+	// They are written through the progress view so they do not break the progress drawn on the terminal.
+	progress := output.NewProgressView(os.Stderr, terminalWidth(os.Stderr))
+	handler := slog.NewTextHandler(progress, nil)
 	logger := slog.New(handler)
+
+	// The connection flags are read when the providers are registered
+	executableName := getExecutableName()
+	command := kong.Parse(&CLI, kong.Name(executableName), kong.Description(AppDescription))
+
 	container := dig.New()
 
 	err := container.Provide(func() *slog.Logger {
@@ -34,43 +49,47 @@ func main() { // coverage-ignore
 		os.Exit(1)
 	}
 
-	err = container.Provide(app.ProvideStreamCommandHandler)
-	if err != nil {
-		logger.Error("Failed to provide stream command handler", slog.String("error", err.Error()))
-		os.Exit(1)
-	}
-
-	err = container.Provide(app.ProvideConfigReader)
+	err = container.Provide(func() (output.Printer, error) {
+		return output.NewPrinter(CLI.Output, os.Stdout)
+	})
 	if err != nil {
-		logger.Error("Failed to provide config reader", slog.String("error", err.Error()))
+		logger.Error("Failed to provide output printer", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
 
-	err = container.Provide(common.ProvideStreamClassDiscoveryService)
+	err = container.Provide(func() *output.ProgressView {
+		return progress
+	})
 	if err != nil {
-		logger.Error("Failed to provide stream class discovery service", slog.String("error", err.Error()))
+		logger.Error("Failed to provide progress view", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
 
-	err = container.Provide(common.ProvideDynamicClient)
+	err = container.Provide(func() *prompt.Prompter {
+		// The questions go to stderr to keep stdout for the command output
+		return prompt.NewPrompter(os.Stdin, os.Stderr, term.IsTerminal(int(os.Stdin.Fd())))
+	})
 	if err != nil {
-		logger.Error("Failed to provide dynamic client", slog.String("error", err.Error()))
+		logger.Error("Failed to provide prompter", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
 
-	err = container.Provide(v0.ProvideStreamClassOperationService)
+	err = app.RegisterProviders(container, &models.ConnectionOptions{
+		Kubeconfig: CLI.Kubeconfig,
+		Context:    CLI.Context,
+		Namespace:  CLI.Namespace,
+	})
 	if err != nil {
-		logger.Error("Failed to provide stream class operation service", slog.String("error", err.Error()))
+		logger.Error("Failed to register providers", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
 
-	executableName := getExecutableName()
-	command := kong.Parse(&CLI, kong.Name(executableName), kong.Description(AppDescription))
 	err = command.Run(container)
 
 	if err != nil {
-		logger.Error("Command execution failed", slog.String("command", command.Command()), slog.String("error", err.Error()))
-		os.Exit(1)
+		exitCode := app.ExitCode(err)
+		logger.Error("Command execution failed", slog.String("command", command.Command()), slog.String("error", err.Error()), slog.Int("exitCode", exitCode))
+		os.Exit(exitCode)
 	}
 	logger.Info("Command executed successfully", slog.String("command", command.Command()))
 }
@@ -79,3 +98,15 @@ func getExecutableName() string { // coverage-ignore
 	// Not checking for errors here since argv[0] should always be available
 	return os.Args[0]
 }
+
+// terminalWidth returns the width of the terminal the file is attached to, or zero if it is not a terminal.
+func terminalWidth(file *os.File) int { // coverage-ignore
+	if !term.IsTerminal(int(file.Fd())) {
+		return 0
+	}
+	width, _, err := term.GetSize(int(file.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
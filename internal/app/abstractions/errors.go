@@ -0,0 +1,116 @@
+package abstractions
+
+import (
+	"errors"
+	"fmt"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"strings"
+)
+
+// ErrJobNotFound is returned when the stream job does not exist, e.g. because the stream is suspended.
+var ErrJobNotFound = errors.New("stream job not found")
+
+// ErrAnnotationMissing is returned when the stream job does not have the annotations referencing the stream resource.
+var ErrAnnotationMissing = errors.New("stream job annotations are missing")
+
+// StreamNotFoundError is returned when the stream does not exist.
+type StreamNotFoundError struct {
+	Id        string
+	Namespace string
+}
+
+func (e *StreamNotFoundError) Error() string {
+	return fmt.Sprintf("stream %s not found in namespace %s", e.Id, e.Namespace)
+}
+
+// StreamClassNotFoundError is returned when the stream class does not exist.
+type StreamClassNotFoundError struct {
+	StreamClass string
+	Namespace   string
+}
+
+func (e *StreamClassNotFoundError) Error() string {
+	return fmt.Sprintf("stream class %s not found in namespace %s", e.StreamClass, e.Namespace)
+}
+
+// WaitTimeoutError is returned when the stream does not reach the phase before the deadline.
+// The waits for something other than a phase, e.g. the deletion, describe it with the Condition instead.
+type WaitTimeoutError struct {
+	Id        string
+	Phase     string
+	Condition string
+	Err       error
+}
+
+func (e *WaitTimeoutError) Error() string {
+	if e.Phase == "" {
+		return fmt.Sprintf("timed out waiting for stream %s %s: %v", e.Id, e.Condition, e.Err)
+	}
+	return fmt.Sprintf("timed out waiting for stream %s to reach phase %s: %v", e.Id, e.Phase, e.Err)
+}
+
+func (e *WaitTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// AmbiguousStreamError is returned when streams with the same ID exist in several stream classes.
+type AmbiguousStreamError struct {
+	Id            string
+	StreamClasses []string
+}
+
+func (e *AmbiguousStreamError) Error() string {
+	return fmt.Sprintf("stream %s exists in several stream classes: %s, specify the stream class with --class", e.Id, strings.Join(e.StreamClasses, ", "))
+}
+
+// StreamFailedError is returned when the stream enters the Failed phase, or gets a terminal condition,
+// while waiting for another phase.
+type StreamFailedError struct {
+	Id         string
+	Phase      string
+	Message    string
+	Conditions []models.StreamCondition
+
+	// JobFailureReason is the reason of the last failure of the stream job, if known.
+	JobFailureReason string
+}
+
+func (e *StreamFailedError) Error() string {
+	message := fmt.Sprintf("stream %s failed in phase %s", e.Id, e.Phase)
+	if e.Message != "" {
+		message += ": " + e.Message
+	}
+	for _, condition := range e.Conditions {
+		message += fmt.Sprintf("; condition %s=%s", condition.Type, condition.Status)
+		if condition.Reason != "" {
+			message += " " + condition.Reason
+		}
+		if condition.Message != "" {
+			message += ": " + condition.Message
+		}
+	}
+	if e.JobFailureReason != "" {
+		message += "; last job failure: " + e.JobFailureReason
+	}
+	return message
+}
+
+// InvalidManifestError is returned when the stream resource does not match the schema of the stream class.
+type InvalidManifestError struct {
+	Name   string
+	Errors []string
+}
+
+func (e *InvalidManifestError) Error() string {
+	return fmt.Sprintf("stream %s does not match the stream schema: %s", e.Name, strings.Join(e.Errors, "; "))
+}
+
+// StreamDiffersError is returned when the live stream differs from the local manifest.
+type StreamDiffersError struct {
+	Name      string
+	Namespace string
+}
+
+func (e *StreamDiffersError) Error() string {
+	return fmt.Sprintf("stream %s in namespace %s differs from the manifest", e.Name, e.Namespace)
+}
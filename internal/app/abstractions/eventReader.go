@@ -0,0 +1,13 @@
+package abstractions
+
+import (
+	"context"
+
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+)
+
+// EventReader reads the Kubernetes events related to a stream.
+type EventReader interface {
+	// ListStreamEvents returns the events of the stream custom resource of the kind, its job and the job pods.
+	ListStreamEvents(ctx context.Context, id string, namespace string, streamKind string) ([]models.TimelineEntry, error)
+}
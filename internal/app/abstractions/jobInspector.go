@@ -0,0 +1,17 @@
+package abstractions
+
+import (
+	"context"
+
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+)
+
+// JobInspector reads the state of the batch/v1 Job backing a stream.
+type JobInspector interface {
+	// GetJobStatus returns the status of the job and its pods.
+	// It returns nil without an error if the job does not exist.
+	GetJobStatus(ctx context.Context, jobName string, namespace string) (*models.JobStatus, error)
+
+	// WaitForJobDeletion waits until the job does not exist. It returns right away if the job is already removed.
+	WaitForJobDeletion(ctx context.Context, jobName string, namespace string) error
+}
@@ -0,0 +1,15 @@
+package abstractions
+
+import (
+	"context"
+	"io"
+
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+)
+
+// JobLogReader reads the logs of the pods of the batch/v1 Job backing a stream.
+type JobLogReader interface {
+	// ReadLogs writes the logs of the job pods to the writer, prefixing every line with the pod name.
+	// In the follow mode it returns only when the context is cancelled.
+	ReadLogs(ctx context.Context, jobName string, namespace string, options models.LogOptions, writer io.Writer) error
+}
@@ -0,0 +1,25 @@
+package abstractions
+
+import (
+	"context"
+
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ObjectOperator reads and changes the objects referenced by the streams: the stream classes and the job templates.
+// The kind is one of the models.ReferenceKind constants, except the secrets which are never copied.
+type ObjectOperator interface {
+	// Get returns the object of the kind by its name.
+	// It returns nil without an error if the object does not exist.
+	Get(ctx context.Context, kind string, name string, namespace string) (*unstructured.Unstructured, error)
+
+	// Create creates the object of the kind.
+	// In the client dry run mode the object is not sent, in the server dry run mode it is validated, but not persisted.
+	Create(ctx context.Context, kind string, object *unstructured.Unstructured, namespace string, dryRun models.DryRunMode) error
+
+	// Patch applies the JSON merge patch to the object of the kind.
+	// In the dry run mode the patch is only previewed.
+	Patch(ctx context.Context, kind string, name string, namespace string, patch map[string]any, dryRun models.DryRunMode) (*models.PatchResult, error)
+}
@@ -0,0 +1,15 @@
+package abstractions
+
+import (
+	"context"
+
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+)
+
+// ReferenceResolver reads the objects referenced by a stream.
+type ReferenceResolver interface {
+	// Resolve checks that the referenced object exists in the namespace and fills in the reference.
+	// A missing object or secret key is reported in the reference problem, not as an error.
+	// It returns an error if the object could not be read.
+	Resolve(ctx context.Context, reference *models.StreamReference, namespace string) error
+}
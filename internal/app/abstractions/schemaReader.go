@@ -0,0 +1,13 @@
+package abstractions
+
+import (
+	"context"
+
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+)
+
+// SchemaReader reads the schema of the stream resources.
+type SchemaReader interface {
+	// GetStreamSchema returns the OpenAPI v3 schema of the stream resource from its CustomResourceDefinition.
+	GetStreamSchema(ctx context.Context, apiSettings *models.ClientApiSettings) (*models.StreamSchema, error)
+}
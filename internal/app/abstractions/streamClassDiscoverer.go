@@ -7,6 +7,19 @@ import (
 )
 
 type ApiSettingsDiscoverer interface {
+	// DiscoveryFromJobs reads the API settings from the annotations of the stream job.
+	// It returns ErrJobNotFound if the job does not exist and ErrAnnotationMissing if the job is not annotated.
 	DiscoveryFromJobs(ctx context.Context, jobName string, namespace string) (*models.ClientApiSettings, error)
+
+	// DiscoveryFromStreamClass reads the API settings from the stream class.
+	// It returns StreamClassNotFoundError if the stream class does not exist.
 	DiscoveryFromStreamClass(ctx context.Context, streamClass string, namespace string) (*models.ClientApiSettings, error)
+
+	// DiscoveryFromStreamId finds the stream class owning the stream by scanning all stream classes.
+	// It returns the API settings and the name of the stream class, StreamNotFoundError if no stream class
+	// has the stream, or an AmbiguousStreamError if the stream ID exists in several stream classes.
+	DiscoveryFromStreamId(ctx context.Context, id string, namespace string) (*models.ClientApiSettings, string, error)
+
+	// ListStreamClasses returns the names of all stream classes in the namespace.
+	ListStreamClasses(ctx context.Context, namespace string) ([]string, error)
 }
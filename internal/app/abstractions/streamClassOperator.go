@@ -3,6 +3,9 @@ package abstractions
 import (
 	"context"
 	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 type StreamPhase int
@@ -25,17 +28,74 @@ func (ss StreamPhase) String() string { // coverage-ignore
 	return stateName[ss]
 }
 
+// ParseStreamPhase returns the stream phase by its name from the stream status.
+// It returns false if the phase is not known.
+func ParseStreamPhase(name string) (StreamPhase, bool) {
+	for phase, phaseName := range stateName {
+		if strings.EqualFold(phaseName, name) {
+			return phase, true
+		}
+	}
+	return 0, false
+}
+
+// WaitCondition describes the stream phase to wait for after a patch.
+type WaitCondition struct {
+	// Phase is the phase the stream should reach.
+	Phase StreamPhase
+
+	// Patch is the patch that started the transition, if any.
+	// The stream versions written after it are used to detect that a transient phase was already passed.
+	Patch *models.PatchResult
+
+	// PassedPhases are the phases that follow a transient Phase.
+	// Once the stream has observed the Patch, reaching one of them means the Phase was reached and left.
+	PassedPhases []StreamPhase
+
+	// IgnoreFailure keeps waiting when the stream fails, e.g. while a failed stream is suspended before its deletion.
+	IgnoreFailure bool
+}
+
 // StreamClassOperator defines the operations that can be performed on a stream class.
 type StreamClassOperator interface {
 	// Suspend suspends a running stream by its ID.
-	Suspend(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings) error
+	// In the dry run mode the patch is only previewed.
+	Suspend(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings, dryRun models.DryRunMode) (*models.PatchResult, error)
 
 	// Resume resumes a suspended stream by its ID.
-	Resume(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings) error
+	// In the dry run mode the patch is only previewed.
+	Resume(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings, dryRun models.DryRunMode) (*models.PatchResult, error)
 
 	// WaitForStatus waits for the stream to reach the desired status.
-	WaitForStatus(ctx context.Context, status StreamPhase, id string, namespace string, apiSettings *models.ClientApiSettings) error
+	// The current state of the stream is checked first, so a phase reached before the call is not missed.
+	WaitForStatus(ctx context.Context, condition WaitCondition, id string, namespace string, apiSettings *models.ClientApiSettings) error
 
 	// Backfill restarts the stream in backfill mode.
-	Backfill(ctx context.Context, id string, namespace string, clientApiSettings *models.ClientApiSettings) error
+	// In the dry run mode the patch is only previewed.
+	Backfill(ctx context.Context, id string, namespace string, clientApiSettings *models.ClientApiSettings, dryRun models.DryRunMode) (*models.PatchResult, error)
+
+	// Patch applies the JSON merge patch to the stream resource.
+	// In the dry run mode the patch is only previewed.
+	Patch(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings, patch map[string]any, dryRun models.DryRunMode) (*models.PatchResult, error)
+
+	// Create creates the stream resource and returns the created object.
+	// In the server dry run mode the stream is validated by the API server, but not persisted.
+	Create(ctx context.Context, stream *unstructured.Unstructured, namespace string, apiSettings *models.ClientApiSettings, dryRun models.DryRunMode) (*unstructured.Unstructured, error)
+
+	// Delete deletes the stream resource, the cascade mode defines what happens to the resources owned by the stream.
+	// In the dry run mode the deletion is only previewed.
+	Delete(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings, cascade models.CascadeMode, dryRun models.DryRunMode) error
+
+	// WaitForDeletion waits until the stream resource does not exist, e.g. until its finalizers complete.
+	WaitForDeletion(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings) error
+
+	// GetStream returns the stream custom resource.
+	// It returns StreamNotFoundError if the stream does not exist.
+	GetStream(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings) (*unstructured.Unstructured, error)
+
+	// GetStatus returns the current status of the stream.
+	GetStatus(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings) (*models.StreamStatus, error)
+
+	// List returns the streams of the stream class in the namespace matching the label selector.
+	List(ctx context.Context, namespace string, labelSelector string, apiSettings *models.ClientApiSettings) ([]*models.StreamSummary, error)
 }
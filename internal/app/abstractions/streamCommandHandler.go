@@ -1,38 +1,176 @@
 package abstractions
 
-import "context"
+import (
+	"context"
+	"io"
+
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+)
 
 type StreamSuspendHandlerer interface {
 
-	/// Suspends the stream with the given ID.
-	/// It returns an error if the operation fails.
-	Suspend(ctx context.Context, id string) error
+	/// Suspends the stream with the given ID in the namespace.
+	/// The stream class is discovered from the stream ID if it is empty and the stream job does not exist.
+	/// It returns the operation result or an error if the operation fails.
+	Suspend(ctx context.Context, id string, namespace string, streamClass string, options models.OperationOptions) (*models.OperationResult, error)
 }
 
 type StreamResumeHandlerer interface {
 
-	/// Resumes the stream with the given ID.
-	/// It returns an error if the operation fails.
-	Resume(ctx context.Context, id string, streamClass string) error
+	/// Resumes the stream with the given ID in the namespace.
+	/// The stream class is discovered from the stream ID if it is empty and the stream job does not exist.
+	/// It returns the operation result or an error if the operation fails.
+	Resume(ctx context.Context, id string, namespace string, streamClass string, options models.OperationOptions) (*models.OperationResult, error)
 }
 
 type StreamBackfillHandler interface {
 
 	/// Backfill restarts the stream with the given ID in backfill mode.
-	/// It returns an error if the operation fails.
-	Backfill(ctx context.Context, id string, streamClass string, watch bool) error
+	/// The stream class is discovered from the stream ID if it is empty and the stream job does not exist.
+	/// It returns the operation result or an error if the operation fails.
+	Backfill(ctx context.Context, id string, namespace string, streamClass string, options models.OperationOptions) (*models.OperationResult, error)
 }
 
 type StreamRestartHandler interface {
 
-	/// Backfill restarts the stream with the given ID in backfill mode.
+	/// Restart restarts the stream with the given ID in streaming mode.
+	/// The stream class is discovered from the stream ID if it is empty and the stream job does not exist.
+	/// It returns the operation result or an error if the operation fails.
+	Restart(ctx context.Context, id string, namespace string, streamClass string, options models.OperationOptions) (*models.OperationResult, error)
+}
+
+type StreamListHandler interface {
+
+	/// List returns the streams in the namespace matching the filter.
+	/// It returns an error if the operation fails.
+	List(ctx context.Context, namespace string, filter models.StreamFilter) (*models.StreamList, error)
+}
+
+type StreamStatusHandler interface {
+
+	/// Status returns the state of the stream with the given ID and its backing job.
+	/// The stream class is discovered from the stream ID if it is empty and the stream job does not exist.
 	/// It returns an error if the operation fails.
-	Restart(ctx context.Context, id string, wait bool) error
+	Status(ctx context.Context, id string, namespace string, streamClass string) (*models.StreamStatus, error)
+}
+
+type StreamDescribeHandler interface {
+
+	/// Describe returns the spec of the stream with the given ID and the objects it references:
+	/// the stream class, the connection secret and the job templates. The broken references are flagged,
+	/// the secret values are never read.
+	/// The stream class is discovered from the stream ID if it is empty and the stream job does not exist.
+	/// It returns an error if the operation fails.
+	Describe(ctx context.Context, id string, namespace string, streamClass string) (*models.StreamDescription, error)
+}
+
+type StreamCreateHandler interface {
+
+	/// Scaffold builds the skeleton of a new stream of the stream class from the schema of the stream resource.
+	/// The skeleton has the required fields set to the default or zero values and the suspended state.
+	/// It returns an error if the operation fails.
+	Scaffold(ctx context.Context, namespace string, streamClass string, name string) (*models.StreamTemplate, error)
+
+	/// Create validates the manifest against the schema of the stream resource and creates the stream.
+	/// The stream is created suspended unless the options request a running stream.
+	/// It returns InvalidManifestError if the manifest does not match the schema or an error if the operation fails.
+	Create(ctx context.Context, namespace string, streamClass string, name string, manifest map[string]any, options models.CreateOptions) (*models.OperationResult, error)
+}
+
+type StreamUpdateHandler interface {
+
+	/// Set changes the fields of the spec of the stream with the given ID. The values are converted to the field types
+	/// of the stream schema and the changed stream is validated against the schema before it is patched.
+	/// The stream class is discovered from the stream ID if it is empty and the stream job does not exist.
+	/// It returns the operation result with the diff of the stream, InvalidManifestError if the changed stream
+	/// does not match the schema or an error if the operation fails.
+	Set(ctx context.Context, id string, namespace string, streamClass string, values []models.FieldValue, options models.UpdateOptions) (*models.OperationResult, error)
+
+	/// Edit passes the manifest of the stream with the given ID to the edit function, validates the edited manifest
+	/// against the stream schema and patches the stream with the changes.
+	/// The stream class is discovered from the stream ID if it is empty and the stream job does not exist.
+	/// It returns the operation result with the diff of the stream, InvalidManifestError if the edited stream
+	/// does not match the schema or an error if the operation fails.
+	Edit(ctx context.Context, id string, namespace string, streamClass string, edit models.EditFunc, options models.UpdateOptions) (*models.OperationResult, error)
+}
+
+type StreamDiffHandler interface {
+
+	/// Diff compares the manifest with the live stream of the same name. The stream class is the one serving
+	/// the kind of the manifest if it is empty. The status, the fields maintained by the API server and,
+	/// unless the options include it, the arcane/state annotation are not compared.
+	/// It returns the diff, empty if the stream does not differ, or an error if the operation fails.
+	Diff(ctx context.Context, namespace string, streamClass string, manifest map[string]any, options models.DiffOptions) (*models.StreamDiff, error)
+}
+
+type StreamCloneHandler interface {
+
+	/// Clone copies the spec of the stream with the given ID to a new suspended stream, with the fields overridden
+	/// by the option values. The new stream is created by the target handler, which may be connected to another cluster,
+	/// or by this handler if the target is nil.
+	/// The stream class is discovered from the stream ID if it is empty and the stream job does not exist.
+	/// It returns the operation result, InvalidManifestError if the copy does not match the schema
+	/// or an error if the operation fails.
+	Clone(ctx context.Context, id string, namespace string, streamClass string, options models.CloneOptions, target StreamCreateHandler) (*models.OperationResult, error)
+}
+
+type StreamBundleHandler interface {
+
+	/// Export reads the streams with the given IDs, or the streams matching the filter if no IDs are given,
+	/// together with their stream classes and job templates. The connection secrets are not exported.
+	/// The objects are cleaned of the namespace, the status and the fields maintained by the cluster.
+	/// It returns an error if a stream or a referenced object cannot be read.
+	Export(ctx context.Context, namespace string, ids []string, filter models.StreamFilter) (*models.StreamBundle, error)
+
+	/// Import creates the objects of the bundle missing in the namespace and patches the changed ones,
+	/// the stream classes and job templates are imported before the streams. A failure for one object
+	/// does not stop the import of the others.
+	/// It returns the outcome for every object or an error if the import cannot start.
+	Import(ctx context.Context, namespace string, bundle *models.StreamBundle, options models.ImportOptions) (*models.BundleResult, error)
+}
+
+type StreamDeleteHandler interface {
+
+	/// Delete deletes the stream with the given ID. Unless forced, the stream is suspended first and deleted
+	/// once the operator has stopped it and removed its job, so the job is not killed in the middle of a batch.
+	/// The stream class is discovered from the stream ID if it is empty and the stream job does not exist.
+	/// It returns the operation result or an error if the operation fails.
+	Delete(ctx context.Context, id string, namespace string, streamClass string, options models.DeleteOptions) (*models.OperationResult, error)
+}
+
+type StreamLogsHandler interface {
+
+	/// Logs writes the logs of the pods of the stream job to the writer.
+	/// In the follow mode it keeps streaming the logs until the context is cancelled.
+	/// It returns an error if the operation fails.
+	Logs(ctx context.Context, id string, namespace string, options models.LogOptions, writer io.Writer) error
+}
+
+type StreamEventsHandler interface {
+
+	/// Events returns the chronological timeline of the events of the stream, its job and pods
+	/// merged with the transitions of the stream status conditions.
+	/// It returns an error if the operation fails.
+	Events(ctx context.Context, id string, namespace string, streamClass string) (*models.StreamTimeline, error)
 }
 
 type StreamCommandHandler interface {
+	StreamListHandler
+	StreamStatusHandler
+	StreamDescribeHandler
+	StreamCreateHandler
+	StreamUpdateHandler
+	StreamDiffHandler
+	StreamCloneHandler
+	StreamBundleHandler
+	StreamDeleteHandler
 	StreamSuspendHandlerer
 	StreamResumeHandlerer
 	StreamBackfillHandler
 	StreamRestartHandler
+	StreamLogsHandler
+	StreamEventsHandler
 }
+
+// StreamCommandHandlerFactory creates the stream command handler connected to the cluster of the kubeconfig context.
+type StreamCommandHandlerFactory func(kubeContext string) (StreamCommandHandler, error)
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"sync"
+)
+
+// StreamOperation is an operation applied to a single stream of a bulk operation.
+type StreamOperation func(stream *models.StreamSummary) (*models.OperationResult, error)
+
+// RunBulkOperation applies the operation to every stream running at most parallelism operations at the same time.
+// A failure for one stream does not stop the operation for the others; the result keeps the order of the streams.
+func RunBulkOperation(streams []*models.StreamSummary, parallelism int, operation StreamOperation) *models.BulkOperationResult {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	items := make([]*models.BulkOperationItem, len(streams))
+	semaphore := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i, stream := range streams {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			item := &models.BulkOperationItem{Name: stream.Name, StreamClass: stream.StreamClass}
+			result, err := operation(stream)
+			if err != nil {
+				item.Error = err.Error()
+			} else {
+				item.Result = result
+			}
+			items[i] = item
+		}()
+	}
+	wg.Wait()
+
+	return &models.BulkOperationResult{Items: items}
+}
@@ -50,3 +50,16 @@ func (r *execConfigReader) ReadConfig() (*rest.Config, error) {
 	// Parse the output as kubeconfig
 	return clientcmd.RESTConfigFromKubeConfig(output)
 }
+
+func (r *execConfigReader) ReadNamespace() (string, error) {
+	output, err := exec.Command(*r.command, r.arguments...).Output()
+	if err != nil { // coverage-ignore
+		return "", fmt.Errorf("failed to execute command %s: %w", *r.command, err)
+	}
+
+	clientConfig, err := clientcmd.NewClientConfigFromBytes(output)
+	if err != nil { // coverage-ignore
+		return "", fmt.Errorf("failed to parse kube config: %w", err)
+	}
+	return resolveNamespace(clientConfig, "")
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"errors"
+	"net/url"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Exit codes of the plugin. Invalid command line arguments exit with 80.
+const (
+	// ExitCodeSuccess is returned when the command succeeds.
+	ExitCodeSuccess = 0
+
+	// ExitCodeError is returned for the failures not covered by the other exit codes.
+	ExitCodeError = 1
+
+	// ExitCodeStreamDiffers is returned by the diff command when the live stream differs from the manifest,
+	// the same code kubectl diff exits with.
+	ExitCodeStreamDiffers = 1
+
+	// ExitCodeStreamNotFound is returned when the stream does not exist.
+	ExitCodeStreamNotFound = 2
+
+	// ExitCodeStreamClassNotFound is returned when the stream class does not exist.
+	ExitCodeStreamClassNotFound = 3
+
+	// ExitCodeAnnotationMissing is returned when the stream job does not reference the stream resource.
+	ExitCodeAnnotationMissing = 4
+
+	// ExitCodeInvalidTransition is returned when the operation is not valid in the current stream state.
+	ExitCodeInvalidTransition = 5
+
+	// ExitCodeTimeout is returned when the stream does not reach the phase before the deadline.
+	ExitCodeTimeout = 6
+
+	// ExitCodeStreamFailed is returned when the stream enters the Failed phase while the command waits for it.
+	ExitCodeStreamFailed = 7
+
+	// ExitCodeApiError is returned when the API server rejects the request, including authentication
+	// and authorization failures, or cannot be reached.
+	ExitCodeApiError = 8
+
+	// ExitCodeInvalidManifest is returned when the stream resource does not match the schema of the stream class.
+	ExitCodeInvalidManifest = 9
+)
+
+// ExitCode returns the exit code for the error returned by a command.
+// The more specific failure classes take precedence over the API errors they are caused by.
+func ExitCode(err error) int {
+	var (
+		streamNotFound      *abstractions.StreamNotFoundError
+		streamClassNotFound *abstractions.StreamClassNotFoundError
+		invalidTransition   *InvalidTransitionError
+		timeout             *abstractions.WaitTimeoutError
+		streamFailed        *abstractions.StreamFailedError
+		invalidManifest     *abstractions.InvalidManifestError
+		streamDiffers       *abstractions.StreamDiffersError
+		apiStatus           apierrors.APIStatus
+		urlError            *url.Error
+	)
+	switch {
+	case err == nil:
+		return ExitCodeSuccess
+	case errors.As(err, &streamNotFound):
+		return ExitCodeStreamNotFound
+	case errors.As(err, &streamClassNotFound):
+		return ExitCodeStreamClassNotFound
+	case errors.Is(err, abstractions.ErrAnnotationMissing):
+		return ExitCodeAnnotationMissing
+	case errors.As(err, &invalidTransition):
+		return ExitCodeInvalidTransition
+	case errors.As(err, &streamFailed):
+		return ExitCodeStreamFailed
+	case errors.As(err, &timeout):
+		return ExitCodeTimeout
+	case errors.As(err, &invalidManifest):
+		return ExitCodeInvalidManifest
+	case errors.As(err, &streamDiffers):
+		return ExitCodeStreamDiffers
+	case errors.As(err, &apiStatus), errors.As(err, &urlError):
+		return ExitCodeApiError
+	default:
+		return ExitCodeError
+	}
+}
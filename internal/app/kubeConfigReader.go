@@ -0,0 +1,73 @@
+package app
+
+import (
+	"fmt"
+
+	"s-vitaliy/kubectl-plugin-arcane/internal/client/api/common"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// DefaultNamespace is the namespace used when neither the flags nor the kubeconfig context define one.
+const DefaultNamespace = "arcane"
+
+var _ common.ConfigReader = (*kubeConfigReader)(nil)
+
+// ProvideConfigReader provides a config reader that follows the kubectl loading rules:
+// the --kubeconfig flag, the $KUBECONFIG paths, the default kubeconfig file and the in-cluster config.
+func ProvideConfigReader(options *models.ConnectionOptions) (common.ConfigReader, error) { // coverage-ignore, the code is trivial
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = options.Kubeconfig
+
+	overrides := &clientcmd.ConfigOverrides{
+		CurrentContext: options.Context,
+		Context:        clientcmdapi.Context{Namespace: options.Namespace},
+	}
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+	return &kubeConfigReader{clientConfig: clientConfig, context: options.Context}, nil
+}
+
+type kubeConfigReader struct {
+	clientConfig clientcmd.ClientConfig
+	context      string
+}
+
+func (r *kubeConfigReader) ReadConfig() (*rest.Config, error) { // coverage-ignore, the code is trivial
+	config, err := r.clientConfig.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kube config: %w", err)
+	}
+	return config, nil
+}
+
+func (r *kubeConfigReader) ReadNamespace() (string, error) {
+	return resolveNamespace(r.clientConfig, r.context)
+}
+
+// resolveNamespace returns the namespace from the overrides or the kubeconfig context.
+// It falls back to DefaultNamespace instead of "default" when the context does not set a namespace.
+func resolveNamespace(clientConfig clientcmd.ClientConfig, contextOverride string) (string, error) {
+	namespace, overridden, err := clientConfig.Namespace()
+	if err != nil {
+		return "", fmt.Errorf("failed to read namespace from kube config: %w", err)
+	}
+	if overridden || namespace != "default" {
+		return namespace, nil
+	}
+
+	rawConfig, err := clientConfig.RawConfig()
+	if err != nil {
+		return DefaultNamespace, nil
+	}
+	currentContext := rawConfig.CurrentContext
+	if contextOverride != "" {
+		currentContext = contextOverride
+	}
+	if context, ok := rawConfig.Contexts[currentContext]; ok && context.Namespace != "" {
+		return namespace, nil
+	}
+	return DefaultNamespace, nil
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// progressInterval is the interval between the progress reports while waiting for the stream.
+	progressInterval = 2 * time.Second
+
+	// progressLogLines is the number of the last log lines of the stream job included in the progress.
+	progressLogLines = 5
+)
+
+// operationProgress reports the progress of a single stream operation.
+type operationProgress struct {
+	report models.ProgressFunc
+	base   models.WaitProgress
+}
+
+// newOperationProgress creates the progress of the operation, or returns nil if the progress is not requested.
+func newOperationProgress(ctx context.Context, id string, namespace string, operation string, options models.OperationOptions) *operationProgress {
+	if options.Progress == nil {
+		return nil
+	}
+	progress := &operationProgress{
+		report: options.Progress,
+		base:   models.WaitProgress{Name: id, Namespace: namespace, Operation: operation, Started: time.Now()},
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		progress.base.Deadline = deadline
+	}
+	return progress
+}
+
+// watchProgress reports the progress of the wait for the target phase until the returned function is called.
+func (handler *SyncronousCommandHandler) watchProgress(ctx context.Context, progress *operationProgress, target abstractions.StreamPhase, clientApiSettings *models.ClientApiSettings) func() {
+	if progress == nil {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			snapshot := handler.readProgress(ctx, progress.base, target, clientApiSettings)
+			if ctx.Err() != nil {
+				return
+			}
+			progress.report(snapshot)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// readProgress reads the current phase of the stream, its job and the last lines of the job logs.
+// The parts that cannot be read are left empty, the progress is informational only.
+func (handler *SyncronousCommandHandler) readProgress(ctx context.Context, base models.WaitProgress, target abstractions.StreamPhase, clientApiSettings *models.ClientApiSettings) *models.WaitProgress {
+	snapshot := base
+	snapshot.TargetPhase = target.String()
+
+	status, err := handler.streamClassOperator.GetStatus(ctx, base.Name, base.Namespace, clientApiSettings)
+	if err != nil {
+		handler.logger.Debug("Failed to read stream status for progress", "id", base.Name, "error", err)
+	} else {
+		snapshot.Phase = status.Phase
+	}
+
+	snapshot.Job, err = handler.jobInspector.GetJobStatus(ctx, base.Name, base.Namespace)
+	if err != nil {
+		handler.logger.Debug("Failed to read stream job for progress", "id", base.Name, "error", err)
+	}
+	if snapshot.Job == nil {
+		return &snapshot
+	}
+
+	var logs bytes.Buffer
+	err = handler.jobLogReader.ReadLogs(ctx, base.Name, base.Namespace, models.LogOptions{TailLines: progressLogLines}, &logs)
+	if err != nil {
+		handler.logger.Debug("Failed to read stream logs for progress", "id", base.Name, "error", err)
+	}
+	snapshot.LogLines = lastLines(logs.String(), progressLogLines)
+	return &snapshot
+}
+
+// lastLines returns up to count last non-empty lines of the text.
+func lastLines(text string, count int) []string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return nil
+	}
+	return lines[max(len(lines)-count, 0):]
+}
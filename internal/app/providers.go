@@ -0,0 +1,71 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/client/api/common"
+	v0 "s-vitaliy/kubectl-plugin-arcane/internal/client/api/v0"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+
+	"go.uber.org/dig"
+)
+
+// RegisterProviders registers the stream command handler, the services it uses and the clients connected
+// to the cluster of the connection options. The container must provide the logger.
+func RegisterProviders(container *dig.Container, connectionOptions *models.ConnectionOptions) error {
+	providers := []any{
+		func() *models.ConnectionOptions { return connectionOptions },
+		ProvideConfigReader,
+		common.ProvideDynamicClient,
+		common.ProvideKubernetesClient,
+		common.ProvideStreamClassDiscoveryService,
+		common.ProvideJobInspectionService,
+		common.ProvideJobLogService,
+		common.ProvideEventService,
+		common.ProvideReferenceResolutionService,
+		common.ProvideSchemaDiscoveryService,
+		common.ProvideObjectOperationService,
+		v0.ProvideStreamClassOperationService,
+		ProvideStreamCommandHandler,
+		ProvideStreamCommandHandlerFactory,
+	}
+	for _, provider := range providers {
+		if err := container.Provide(provider); err != nil {
+			return fmt.Errorf("failed to register provider: %w", err)
+		}
+	}
+	return nil
+}
+
+// ProvideStreamCommandHandlerFactory provides the factory of the stream command handlers connected to
+// another kubeconfig context, the commands working with two clusters use it for the second one.
+// Each handler is resolved from a fresh container with the providers registered for the context.
+func ProvideStreamCommandHandlerFactory(logger *slog.Logger, connectionOptions *models.ConnectionOptions) abstractions.StreamCommandHandlerFactory {
+	return func(kubeContext string) (abstractions.StreamCommandHandler, error) {
+		connection := *connectionOptions
+		connection.Context = kubeContext
+
+		container := dig.New()
+		err := container.Provide(func() *slog.Logger {
+			return logger
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := RegisterProviders(container, &connection); err != nil {
+			return nil, err
+		}
+		return ResolveStreamCommandHandler(container)
+	}
+}
+
+// ResolveStreamCommandHandler returns the stream command handler provided by the container.
+func ResolveStreamCommandHandler(container *dig.Container) (abstractions.StreamCommandHandler, error) {
+	var handler abstractions.StreamCommandHandler
+	err := container.Invoke(func(h abstractions.StreamCommandHandler) {
+		handler = h
+	})
+	return handler, err
+}
@@ -0,0 +1,318 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// clusterFields are the metadata fields bound to the cluster the object lives in, they are not exported.
+var clusterFields = []string{"namespace", "ownerReferences", "finalizers", "deletionTimestamp", "deletionGracePeriodSeconds", "generateName"}
+
+func (handler *SyncronousCommandHandler) Export(ctx context.Context, namespace string, ids []string, filter models.StreamFilter) (*models.StreamBundle, error) {
+	handler.logger.Info("Exporting streams", "namespace", namespace, "ids", ids, "streamClass", filter.StreamClass, "selector", filter.LabelSelector)
+	streams, err := handler.exportedStreams(ctx, namespace, ids, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	referenced := map[string]*unstructured.Unstructured{}
+	for _, exported := range streams {
+		references := models.DescriptionFromStreamObject(exported.stream).References
+		references = append(references, models.StreamReference{Kind: models.ReferenceKindStreamClass, Name: exported.streamClass})
+		for _, reference := range references {
+			key := reference.Kind + "/" + reference.Name
+			if reference.Kind == models.ReferenceKindSecret || reference.Name == "" || referenced[key] != nil {
+				continue
+			}
+			object, err := handler.objectOperator.Get(ctx, reference.Kind, reference.Name, namespace)
+			if err != nil {
+				return nil, err
+			}
+			if object == nil {
+				return nil, fmt.Errorf("%s %s referenced by stream %s not found in namespace %s", reference.Kind, reference.Name, exported.stream.GetName(), namespace)
+			}
+			referenced[key] = portableObject(object)
+		}
+	}
+
+	bundle := &models.StreamBundle{}
+	for _, object := range referenced {
+		bundle.Objects = append(bundle.Objects, object)
+	}
+	// The referenced objects go first in a stable order, so they are created before the streams referencing them
+	sort.Slice(bundle.Objects, func(i, j int) bool {
+		if bundle.Objects[i].GetKind() != bundle.Objects[j].GetKind() {
+			return bundle.Objects[i].GetKind() == models.ReferenceKindStreamClass
+		}
+		return bundle.Objects[i].GetName() < bundle.Objects[j].GetName()
+	})
+	for _, exported := range streams {
+		bundle.Objects = append(bundle.Objects, portableObject(exported.stream))
+	}
+	return bundle, nil
+}
+
+// exportedStream is the stream resource read for the export and the stream class serving it.
+type exportedStream struct {
+	stream      *unstructured.Unstructured
+	streamClass string
+}
+
+// exportedStreams reads the streams with the IDs, or the streams matching the filter if there are no IDs.
+func (handler *SyncronousCommandHandler) exportedStreams(ctx context.Context, namespace string, ids []string, filter models.StreamFilter) ([]exportedStream, error) {
+	var streams []exportedStream
+	if len(ids) > 0 {
+		for _, id := range ids {
+			clientApiSettings, streamClass, err := handler.discover(ctx, id, namespace, filter.StreamClass)
+			if err != nil {
+				return nil, err
+			}
+			streamClass, err = handler.requireStreamClass(ctx, id, namespace, clientApiSettings, streamClass)
+			if err != nil {
+				return nil, err
+			}
+			stream, err := handler.streamClassOperator.GetStream(ctx, id, namespace, clientApiSettings)
+			if err != nil {
+				return nil, err
+			}
+			streams = append(streams, exportedStream{stream: stream, streamClass: streamClass})
+		}
+		return streams, nil
+	}
+
+	list, err := handler.List(ctx, namespace, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Items) == 0 {
+		return nil, fmt.Errorf("no streams found matching the selection")
+	}
+	for _, summary := range list.Items {
+		clientApiSettings, err := handler.apiSettingsDiscoverer.DiscoveryFromStreamClass(ctx, summary.StreamClass, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover stream class %s: %w", summary.StreamClass, err)
+		}
+		stream, err := handler.streamClassOperator.GetStream(ctx, summary.Name, namespace, clientApiSettings)
+		if err != nil {
+			return nil, err
+		}
+		streams = append(streams, exportedStream{stream: stream, streamClass: summary.StreamClass})
+	}
+	return streams, nil
+}
+
+func (handler *SyncronousCommandHandler) Import(ctx context.Context, namespace string, bundle *models.StreamBundle, options models.ImportOptions) (*models.BundleResult, error) {
+	handler.logger.Info("Importing stream bundle", "namespace", namespace, "objects", len(bundle.Objects), "keepState", options.KeepState, "dryRun", options.DryRun)
+	result := &models.BundleResult{}
+	if options.DryRun.IsDryRun() {
+		result.DryRun = options.DryRun
+	}
+
+	var streams []*unstructured.Unstructured
+	for _, object := range bundle.Objects {
+		switch object.GetKind() {
+		case models.ReferenceKindStreamClass, models.ReferenceKindJobTemplate:
+			operation, err := handler.importObject(ctx, namespace, object, options.DryRun)
+			result.Items = append(result.Items, bundleItem(object, operation, err))
+		default:
+			streams = append(streams, object)
+		}
+	}
+	if len(streams) == 0 {
+		return result, nil
+	}
+
+	// The stream classes are read after the import, so the streams find the stream classes of the bundle
+	streamClasses, err := handler.streamClassesByKind(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	// The dry run does not create the stream classes of the bundle, so they are read from the bundle too
+	for _, object := range bundle.Objects {
+		if object.GetKind() != models.ReferenceKindStreamClass {
+			continue
+		}
+		streamClass, err := handler.bundleStreamClass(ctx, object)
+		if err != nil {
+			handler.logger.Debug("Skipping stream class", "streamClass", object.GetName(), "error", err)
+			continue
+		}
+		streamClasses[streamClass.streamSchema.ApiVersion+"/"+streamClass.streamSchema.Kind] = streamClass
+	}
+	for _, stream := range streams {
+		streamClass, ok := streamClasses[stream.GetAPIVersion()+"/"+stream.GetKind()]
+		if !ok {
+			err := fmt.Errorf("no stream class serves %s %s in namespace %s", stream.GetAPIVersion(), stream.GetKind(), namespace)
+			result.Items = append(result.Items, bundleItem(stream, nil, err))
+			continue
+		}
+		operation, err := handler.importStream(ctx, namespace, streamClass, stream, options)
+		result.Items = append(result.Items, bundleItem(stream, operation, err))
+	}
+	return result, nil
+}
+
+// importObject creates the stream class or the job template, or patches the live object if it differs from the imported one.
+func (handler *SyncronousCommandHandler) importObject(ctx context.Context, namespace string, object *unstructured.Unstructured, dryRun models.DryRunMode) (*models.OperationResult, error) {
+	kind, name := object.GetKind(), object.GetName()
+	imported := object.DeepCopy()
+	imported.SetNamespace(namespace)
+	live, err := handler.objectOperator.Get(ctx, kind, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if live == nil {
+		if err := handler.objectOperator.Create(ctx, kind, imported, namespace, dryRun); err != nil {
+			return nil, err
+		}
+		return models.NewOperationResult(name, namespace, "created"), nil
+	}
+
+	original := editableObject(live)
+	keepClusterFields(imported, original)
+	patch, err := mergePatch(original, imported)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build the patch of %s %s: %w", kind, name, err)
+	}
+	if len(patch) == 0 {
+		return unchangedResult(name, namespace, "no changes"), nil
+	}
+	withResourceVersion(patch, live.GetResourceVersion())
+	if _, err := handler.objectOperator.Patch(ctx, kind, name, namespace, patch, dryRun); err != nil {
+		return nil, err
+	}
+	return models.NewOperationResult(name, namespace, "updated"), nil
+}
+
+// importStream creates the stream, or patches the live stream if it differs from the imported one.
+// Unless the options keep the state recorded in the bundle, the new stream is created suspended
+// and the state of the live stream is not changed.
+func (handler *SyncronousCommandHandler) importStream(ctx context.Context, namespace string, streamClass *importedStreamClass, stream *unstructured.Unstructured, options models.ImportOptions) (*models.OperationResult, error) {
+	name := stream.GetName()
+	imported := stream.DeepCopy()
+	imported.SetNamespace(namespace)
+	state := imported.GetAnnotations()[models.StateAnnotation]
+
+	_, err := handler.streamClassOperator.GetStream(ctx, name, namespace, streamClass.clientApiSettings)
+	var notFound *abstractions.StreamNotFoundError
+	if errors.As(err, &notFound) {
+		if !options.KeepState {
+			setAnnotation(imported, models.StateAnnotation, "")
+		}
+		handler.logger.Info("Creating stream", "name", name, "streamClass", streamClass.name, "dryRun", options.DryRun)
+		createOptions := models.CreateOptions{DryRun: options.DryRun, Running: options.KeepState && state != stateSuspended}
+		return handler.createStream(ctx, namespace, name, imported.Object, createOptions, streamClass.clientApiSettings, streamClass.streamSchema)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	updateOptions := models.UpdateOptions{OperationOptions: models.OperationOptions{DryRun: options.DryRun}}
+	return handler.update(ctx, name, namespace, streamClass.name, updateOptions, func(live *unstructured.Unstructured, streamSchema *models.StreamSchema) error {
+		changed := imported.DeepCopy()
+		if !options.KeepState {
+			setAnnotation(changed, models.StateAnnotation, live.GetAnnotations()[models.StateAnnotation])
+		}
+		keepClusterFields(changed, live)
+		live.Object = changed.Object
+		return completeManifest(live, streamSchema, namespace, name)
+	})
+}
+
+// importedStreamClass is the stream class serving the imported streams of its apiVersion/kind.
+type importedStreamClass struct {
+	name              string
+	clientApiSettings *models.ClientApiSettings
+	streamSchema      *models.StreamSchema
+}
+
+// streamClassesByKind returns the stream classes in the namespace by the apiVersion/kind of their streams.
+func (handler *SyncronousCommandHandler) streamClassesByKind(ctx context.Context, namespace string) (map[string]*importedStreamClass, error) {
+	names, err := handler.apiSettingsDiscoverer.ListStreamClasses(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stream classes: %w", err)
+	}
+	streamClasses := map[string]*importedStreamClass{}
+	for _, streamClass := range names {
+		clientApiSettings, streamSchema, err := handler.readSchema(ctx, namespace, streamClass)
+		if err != nil {
+			handler.logger.Debug("Skipping stream class", "streamClass", streamClass, "error", err)
+			continue
+		}
+		streamClasses[streamSchema.ApiVersion+"/"+streamSchema.Kind] = &importedStreamClass{name: streamClass, clientApiSettings: clientApiSettings, streamSchema: streamSchema}
+	}
+	return streamClasses, nil
+}
+
+// bundleStreamClass reads the API settings of the stream class object of the bundle from its spec
+// and the stream schema of its streams from the cluster.
+func (handler *SyncronousCommandHandler) bundleStreamClass(ctx context.Context, object *unstructured.Unstructured) (*importedStreamClass, error) {
+	apiGroup, _, _ := unstructured.NestedString(object.Object, "spec", "apiGroupRef")
+	apiVersion, _, _ := unstructured.NestedString(object.Object, "spec", "apiVersion")
+	apiPlural, _, _ := unstructured.NestedString(object.Object, "spec", "pluralName")
+	if apiGroup == "" || apiVersion == "" || apiPlural == "" {
+		return nil, fmt.Errorf("stream class %s does not define the API of its streams", object.GetName())
+	}
+	clientApiSettings := models.NewClientApiSettings(apiGroup, apiVersion, apiPlural)
+	streamSchema, err := handler.schemaReader.GetStreamSchema(ctx, clientApiSettings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema of stream class %s: %w", object.GetName(), err)
+	}
+	return &importedStreamClass{name: object.GetName(), clientApiSettings: clientApiSettings, streamSchema: streamSchema}, nil
+}
+
+// bundleItem reports the outcome of the import of the bundle object.
+func bundleItem(object *unstructured.Unstructured, result *models.OperationResult, err error) *models.BundleItem {
+	item := &models.BundleItem{Kind: object.GetKind(), Name: object.GetName()}
+	if err != nil {
+		item.Error = err.Error()
+		return item
+	}
+	item.Operation = result.Operation
+	item.Message = result.Message
+	return item
+}
+
+// portableObject returns the copy of the object without the status and the metadata bound to the cluster.
+func portableObject(object *unstructured.Unstructured) *unstructured.Unstructured {
+	portable := editableObject(object)
+	for _, field := range clusterFields {
+		unstructured.RemoveNestedField(portable.Object, "metadata", field)
+	}
+	return portable
+}
+
+// keepClusterFields copies the metadata bound to the cluster from the live object to the imported one,
+// so the patch does not remove e.g. the finalizers of the live object.
+func keepClusterFields(imported *unstructured.Unstructured, live *unstructured.Unstructured) {
+	for _, field := range clusterFields {
+		value, found, _ := unstructured.NestedFieldCopy(live.Object, "metadata", field)
+		if found {
+			_ = unstructured.SetNestedField(imported.Object, value, "metadata", field)
+		}
+	}
+}
+
+// setAnnotation sets the annotation of the object, the empty value removes it.
+// The annotations field is removed with the last annotation, so it does not show up in the diffs and patches.
+func setAnnotation(object *unstructured.Unstructured, key string, value string) {
+	annotations := object.GetAnnotations()
+	if value == "" {
+		delete(annotations, key)
+	} else {
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[key] = value
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	object.SetAnnotations(annotations)
+}
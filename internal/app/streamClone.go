@@ -0,0 +1,60 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func (handler *SyncronousCommandHandler) Clone(ctx context.Context, id string, namespace string, streamClass string, options models.CloneOptions, target abstractions.StreamCreateHandler) (*models.OperationResult, error) {
+	handler.logger.Info("Cloning stream", "id", id, "name", options.Name, "namespace", options.Namespace, "fields", len(options.Values), "dryRun", options.DryRun)
+	clientApiSettings, streamClass, err := handler.discover(ctx, id, namespace, streamClass)
+	if err != nil {
+		return nil, err
+	}
+	source, err := handler.streamClassOperator.GetStream(ctx, id, namespace, clientApiSettings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stream %s: %w", id, err)
+	}
+	streamSchema, err := handler.schemaReader.GetStreamSchema(ctx, clientApiSettings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema of stream %s: %w", id, err)
+	}
+	// The copy is created by the stream class of the source stream
+	streamClass, err = handler.requireStreamClass(ctx, id, namespace, clientApiSettings, streamClass)
+	if err != nil {
+		return nil, err
+	}
+
+	targetNamespace := options.Namespace
+	if targetNamespace == "" {
+		targetNamespace = namespace
+	}
+	stream := copiedStream(source, options.Name, targetNamespace)
+	if err := setFields(stream, streamSchema, options.Values); err != nil {
+		return nil, err
+	}
+
+	if target == nil {
+		target = handler
+	}
+	result, err := target.Create(ctx, targetNamespace, streamClass, options.Name, stream.Object, models.CreateOptions{DryRun: options.DryRun})
+	if err != nil {
+		return nil, err
+	}
+	result.Message = fmt.Sprintf("copy of %s/%s", namespace, id)
+	return result, nil
+}
+
+// copiedStream returns the copy of the stream under the new name and namespace, without the metadata
+// of the source resource and its state, so the new stream is created with the suspended state.
+func copiedStream(source *unstructured.Unstructured, name string, namespace string) *unstructured.Unstructured {
+	stream := portableObject(source)
+	setAnnotation(stream, models.StateAnnotation, "")
+	stream.SetName(name)
+	stream.SetNamespace(namespace)
+	return stream
+}
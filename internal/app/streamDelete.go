@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"strings"
+)
+
+func (handler *SyncronousCommandHandler) Delete(ctx context.Context, id string, namespace string, streamClass string, options models.DeleteOptions) (*models.OperationResult, error) {
+	handler.logger.Info("Deleting stream", "id", id, "cascade", options.Cascade, "force", options.Force, "dryRun", options.DryRun)
+	clientApiSettings, _, err := handler.discover(ctx, id, namespace, streamClass)
+	if err != nil {
+		return nil, err
+	}
+
+	var patches []*models.PatchResult
+	if options.Force {
+		handler.logger.Warn("Deleting the stream without suspending it first", "id", id)
+	} else {
+		patch, err := handler.teardown(ctx, id, namespace, clientApiSettings, options.OperationOptions)
+		if err != nil {
+			return nil, err
+		}
+		if patch != nil {
+			patches = append(patches, patch)
+		}
+	}
+
+	if err := handler.streamClassOperator.Delete(ctx, id, namespace, clientApiSettings, options.Cascade, options.DryRun); err != nil {
+		return nil, err
+	}
+	result := newOperationResult(id, namespace, "deleted", options.OperationOptions, patches...)
+	if options.DryRun.IsDryRun() {
+		result.DryRun = options.DryRun
+		return result, nil
+	}
+	if options.Wait {
+		if err := handler.streamClassOperator.WaitForDeletion(ctx, id, namespace, clientApiSettings); err != nil {
+			return nil, timeoutError(err, &abstractions.WaitTimeoutError{Id: id, Condition: "to be deleted"})
+		}
+	}
+	return result, nil
+}
+
+// teardown suspends the stream and waits for the operator to remove its job, so the job stops before the stream
+// resource is deleted. It returns the suspension patch, or nil if the stream is already suspended.
+func (handler *SyncronousCommandHandler) teardown(ctx context.Context, id string, namespace string, clientApiSettings *models.ClientApiSettings, options models.OperationOptions) (*models.PatchResult, error) {
+	status, err := handler.streamClassOperator.GetStatus(ctx, id, namespace, clientApiSettings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the state of stream %s: %w", id, err)
+	}
+
+	var patch *models.PatchResult
+	if status.State != stateSuspended || !strings.EqualFold(status.Phase, abstractions.StreamPhaseSuspended.String()) {
+		progress := newOperationProgress(ctx, id, namespace, "delete", options)
+		if status.State != stateSuspended {
+			patch, err = handler.streamClassOperator.Suspend(ctx, id, namespace, clientApiSettings, options.DryRun)
+			if err != nil {
+				return nil, fmt.Errorf("failed to suspend stream %s: %w", id, err)
+			}
+		}
+		if !options.DryRun.IsDryRun() {
+			// A failed stream is deleted as well, its failure must not abort the teardown
+			condition := abstractions.WaitCondition{Phase: abstractions.StreamPhaseSuspended, Patch: patch, IgnoreFailure: true}
+			err = handler.waitForStatus(ctx, condition, id, namespace, clientApiSettings, progress)
+			if err != nil {
+				handler.logger.Error("Failed to wait for stream to be suspended", "id", id, "error", err)
+				return nil, fmt.Errorf("failed to wait for stream %s to be suspended before deletion: %w", id, err)
+			}
+		}
+	}
+
+	if options.DryRun.IsDryRun() {
+		return patch, nil
+	}
+	if err := handler.jobInspector.WaitForJobDeletion(ctx, id, namespace); err != nil {
+		handler.logger.Error("Failed to wait for the stream job to be removed", "id", id, "error", err)
+		err = timeoutError(err, &abstractions.WaitTimeoutError{Id: id, Condition: "job to be removed"})
+		return nil, fmt.Errorf("failed to wait for the job of stream %s to be removed before deletion: %w", id, err)
+	}
+	return patch, nil
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/diff"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"s-vitaliy/kubectl-plugin-arcane/internal/schema"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func (handler *SyncronousCommandHandler) Diff(ctx context.Context, namespace string, streamClass string, manifest map[string]any, options models.DiffOptions) (*models.StreamDiff, error) {
+	local := (&unstructured.Unstructured{Object: manifest}).DeepCopy()
+	name := local.GetName()
+	if name == "" {
+		return nil, fmt.Errorf("the manifest has no metadata.name")
+	}
+	if local.GetNamespace() != "" {
+		namespace = local.GetNamespace()
+	}
+	handler.logger.Info("Comparing stream with manifest", "name", name, "namespace", namespace, "streamClass", streamClass, "includeState", options.IncludeState)
+
+	if streamClass == "" {
+		streamClasses, err := handler.streamClassesByKind(ctx, namespace)
+		if err != nil {
+			return nil, err
+		}
+		served, ok := streamClasses[local.GetAPIVersion()+"/"+local.GetKind()]
+		if !ok {
+			return nil, fmt.Errorf("no stream class serves %s %s in namespace %s, specify the stream class with --class", local.GetAPIVersion(), local.GetKind(), namespace)
+		}
+		streamClass = served.name
+		handler.logger.Info("Discovered stream class", "name", name, "streamClass", streamClass)
+	}
+	clientApiSettings, streamSchema, err := handler.readSchema(ctx, namespace, streamClass)
+	if err != nil {
+		return nil, err
+	}
+	if err := completeManifest(local, streamSchema, namespace, name); err != nil {
+		return nil, err
+	}
+	// The API server applies the schema defaults to the live stream, the fields left out of the manifest are not a drift
+	schema.ApplyDefaults(streamSchema.Schema, local.Object)
+
+	result := &models.StreamDiff{Name: name, Namespace: namespace, StreamClass: streamClass, Exists: true}
+	liveYaml := ""
+	live, err := handler.streamClassOperator.GetStream(ctx, name, namespace, clientApiSettings)
+	var notFound *abstractions.StreamNotFoundError
+	switch {
+	case errors.As(err, &notFound):
+		handler.logger.Info("Stream does not exist, the whole manifest is new", "name", name)
+		result.Exists = false
+	case err != nil:
+		return nil, err
+	default:
+		liveYaml, err = manifestYaml(comparableObject(live, options))
+		if err != nil {
+			return nil, err
+		}
+	}
+	localYaml, err := manifestYaml(comparableObject(local, options))
+	if err != nil {
+		return nil, err
+	}
+	result.Diff = diff.Unified("live/"+name, "local/"+name, liveYaml, localYaml)
+	return result, nil
+}
+
+// comparableObject returns the copy of the object without the fields maintained by the cluster and,
+// unless the options include it, without the state annotation.
+func comparableObject(object *unstructured.Unstructured, options models.DiffOptions) *unstructured.Unstructured {
+	comparable := portableObject(object)
+	if !options.IncludeState {
+		setAnnotation(comparable, models.StateAnnotation, "")
+	}
+	return comparable
+}
@@ -0,0 +1,146 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"s-vitaliy/kubectl-plugin-arcane/internal/schema"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+func (handler *SyncronousCommandHandler) Scaffold(ctx context.Context, namespace string, streamClass string, name string) (*models.StreamTemplate, error) {
+	handler.logger.Info("Scaffolding stream", "name", name, "streamClass", streamClass)
+	_, streamSchema, err := handler.readSchema(ctx, namespace, streamClass)
+	if err != nil {
+		return nil, err
+	}
+
+	specSchema, err := schema.Lookup(streamSchema.Schema, "spec")
+	if err != nil {
+		specSchema = map[string]any{}
+	}
+	manifest := map[string]any{
+		"apiVersion": streamSchema.ApiVersion,
+		"kind":       streamSchema.Kind,
+		"metadata": map[string]any{
+			"name":        name,
+			"namespace":   namespace,
+			"annotations": map[string]any{models.StateAnnotation: stateSuspended},
+		},
+		"spec": schema.Skeleton(specSchema),
+	}
+	return &models.StreamTemplate{Manifest: manifest, Fields: schema.RequiredFields(specSchema, "spec")}, nil
+}
+
+func (handler *SyncronousCommandHandler) Create(ctx context.Context, namespace string, streamClass string, name string, manifest map[string]any, options models.CreateOptions) (*models.OperationResult, error) {
+	handler.logger.Info("Creating stream", "name", name, "streamClass", streamClass, "dryRun", options.DryRun)
+	clientApiSettings, streamSchema, err := handler.readSchema(ctx, namespace, streamClass)
+	if err != nil {
+		return nil, err
+	}
+	return handler.createStream(ctx, namespace, name, manifest, options, clientApiSettings, streamSchema)
+}
+
+// createStream creates the stream of the stream class with the API settings and the stream schema.
+func (handler *SyncronousCommandHandler) createStream(ctx context.Context, namespace string, name string, manifest map[string]any, options models.CreateOptions, clientApiSettings *models.ClientApiSettings, streamSchema *models.StreamSchema) (*models.OperationResult, error) {
+	stream := &unstructured.Unstructured{Object: manifest}
+	if err := completeManifest(stream, streamSchema, namespace, name); err != nil {
+		return nil, err
+	}
+	// The scaffolded manifests are suspended, a running stream must not keep their state annotation
+	if !options.Running {
+		setAnnotation(stream, models.StateAnnotation, stateSuspended)
+	} else if stream.GetAnnotations()[models.StateAnnotation] == stateSuspended {
+		setAnnotation(stream, models.StateAnnotation, "")
+	}
+	if err := handler.validateManifest(stream, streamSchema); err != nil {
+		return nil, err
+	}
+
+	result := models.NewOperationResult(name, namespace, "created")
+	if options.DryRun.IsDryRun() {
+		result.DryRun = options.DryRun
+	}
+	var err error
+	if options.DryRun == models.DryRunClient {
+		result.Manifest, err = manifestYaml(stream)
+		return result, err
+	}
+	created, err := handler.streamClassOperator.Create(ctx, stream, namespace, clientApiSettings, options.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	if options.DryRun == models.DryRunServer {
+		result.Manifest, err = manifestYaml(created)
+		return result, err
+	}
+	return result, nil
+}
+
+// readSchema reads the API settings and the stream schema of the stream class.
+func (handler *SyncronousCommandHandler) readSchema(ctx context.Context, namespace string, streamClass string) (*models.ClientApiSettings, *models.StreamSchema, error) {
+	clientApiSettings, err := handler.apiSettingsDiscoverer.DiscoveryFromStreamClass(ctx, streamClass, namespace)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to discover stream class %s: %w", streamClass, err)
+	}
+	streamSchema, err := handler.schemaReader.GetStreamSchema(ctx, clientApiSettings)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read schema of stream class %s: %w", streamClass, err)
+	}
+	return clientApiSettings, streamSchema, nil
+}
+
+// validateManifest checks the stream resource against the stream schema.
+func (handler *SyncronousCommandHandler) validateManifest(stream *unstructured.Unstructured, streamSchema *models.StreamSchema) error {
+	errors, err := schema.Validate(streamSchema.Schema, stream.Object)
+	if err != nil {
+		return err
+	}
+	if len(errors) > 0 {
+		handler.logger.Debug("Stream manifest is invalid", "name", stream.GetName(), "errors", errors)
+		return &abstractions.InvalidManifestError{Name: stream.GetName(), Errors: errors}
+	}
+	return nil
+}
+
+// completeManifest fills in the resource type, the name and the namespace of the stream that are not set in the manifest
+// and rejects the manifests of other resources.
+func completeManifest(stream *unstructured.Unstructured, streamSchema *models.StreamSchema, namespace string, name string) error {
+	if stream.GetAPIVersion() == "" {
+		stream.SetAPIVersion(streamSchema.ApiVersion)
+	}
+	if stream.GetKind() == "" {
+		stream.SetKind(streamSchema.Kind)
+	}
+	if stream.GetAPIVersion() != streamSchema.ApiVersion || stream.GetKind() != streamSchema.Kind {
+		return fmt.Errorf("the manifest describes %s %s, the stream class serves %s %s",
+			stream.GetAPIVersion(), stream.GetKind(), streamSchema.ApiVersion, streamSchema.Kind)
+	}
+
+	if stream.GetName() == "" {
+		stream.SetName(name)
+	}
+	if stream.GetName() != name {
+		return fmt.Errorf("the manifest name %s does not match the stream name %s", stream.GetName(), name)
+	}
+	if stream.GetNamespace() == "" {
+		stream.SetNamespace(namespace)
+	}
+	if stream.GetNamespace() != namespace {
+		return fmt.Errorf("the manifest namespace %s does not match the namespace %s", stream.GetNamespace(), namespace)
+	}
+	return nil
+}
+
+func manifestYaml(stream *unstructured.Unstructured) (string, error) {
+	stream = stream.DeepCopy()
+	stream.SetManagedFields(nil)
+	data, err := yaml.Marshal(stream.Object)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal stream %s: %w", stream.GetName(), err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"fmt"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+)
+
+const (
+	stateSuspended       = "suspended"
+	stateReloadRequested = "reload-requested"
+)
+
+// StreamOperationKind is an operation changing the stream state.
+type StreamOperationKind string
+
+const (
+	OperationSuspend  StreamOperationKind = "suspend"
+	OperationResume   StreamOperationKind = "resume"
+	OperationBackfill StreamOperationKind = "backfill"
+	OperationRestart  StreamOperationKind = "restart"
+)
+
+// TransitionDecision is the outcome of the pre-flight validation of an operation.
+type TransitionDecision int
+
+const (
+	// TransitionAllowed means the operation can be applied.
+	TransitionAllowed TransitionDecision = iota
+
+	// TransitionNoOp means the stream is already in the requested state.
+	TransitionNoOp
+
+	// TransitionRejected means the operation is not valid in the current stream state.
+	TransitionRejected
+)
+
+// Transition is the decision for the operation together with the reason for it.
+type Transition struct {
+	Decision TransitionDecision
+	Reason   string
+}
+
+// InvalidTransitionError is returned when the operation is rejected by the pre-flight validation.
+type InvalidTransitionError struct {
+	Operation StreamOperationKind
+	Id        string
+	Reason    string
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("cannot %s stream %s: %s, use --force to override", e.Operation, e.Id, e.Reason)
+}
+
+// ValidateTransition decides whether the operation is valid for the stream
+// in the given status phase and with the given arcane/state annotation.
+func ValidateTransition(operation StreamOperationKind, phaseName string, state string) Transition {
+	phase, known := abstractions.ParseStreamPhase(phaseName)
+	isPhase := func(expected abstractions.StreamPhase) bool {
+		return known && phase == expected
+	}
+
+	switch operation {
+	case OperationSuspend:
+		// The phase lags behind the annotation, a stream still in phase Suspended may be resuming already
+		if state == stateSuspended {
+			return noOp("the stream is already suspended")
+		}
+	case OperationResume:
+		if state == stateReloadRequested {
+			return rejected("the stream has a pending backfill request that resuming would cancel")
+		}
+		if state != stateSuspended {
+			if isPhase(abstractions.StreamPhaseFailed) {
+				return rejected("the stream is in phase Failed and not suspended, restart it instead")
+			}
+			return noOp("the stream is not suspended")
+		}
+	case OperationBackfill:
+		if isPhase(abstractions.StreamPhaseFailed) {
+			return rejected("the stream is in phase Failed, restart it before running a backfill")
+		}
+		if state == stateReloadRequested || isPhase(abstractions.StreamPhaseBackfill) {
+			return noOp("the stream is already running a backfill")
+		}
+	case OperationRestart:
+		if state == stateReloadRequested || isPhase(abstractions.StreamPhaseBackfill) {
+			return rejected("the stream is running a backfill that restarting would cancel")
+		}
+		if state == stateSuspended {
+			return rejected("the stream is suspended, resume it instead")
+		}
+	}
+	return Transition{Decision: TransitionAllowed}
+}
+
+func noOp(reason string) Transition {
+	return Transition{Decision: TransitionNoOp, Reason: reason}
+}
+
+func rejected(reason string) Transition {
+	return Transition{Decision: TransitionRejected, Reason: reason}
+}
@@ -0,0 +1,221 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/diff"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"s-vitaliy/kubectl-plugin-arcane/internal/schema"
+	"strings"
+
+	jsonpatch "gopkg.in/evanphx/json-patch.v4"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+)
+
+// changeFunc changes the stream resource read from the cluster before it is validated and patched.
+type changeFunc func(stream *unstructured.Unstructured, streamSchema *models.StreamSchema) error
+
+func (handler *SyncronousCommandHandler) Set(ctx context.Context, id string, namespace string, streamClass string, values []models.FieldValue, options models.UpdateOptions) (*models.OperationResult, error) {
+	handler.logger.Info("Setting stream fields", "id", id, "fields", len(values), "dryRun", options.DryRun)
+	return handler.update(ctx, id, namespace, streamClass, options, func(stream *unstructured.Unstructured, streamSchema *models.StreamSchema) error {
+		return setFields(stream, streamSchema, values)
+	})
+}
+
+func (handler *SyncronousCommandHandler) Edit(ctx context.Context, id string, namespace string, streamClass string, edit models.EditFunc, options models.UpdateOptions) (*models.OperationResult, error) {
+	handler.logger.Info("Editing stream", "id", id, "dryRun", options.DryRun)
+	return handler.update(ctx, id, namespace, streamClass, options, func(stream *unstructured.Unstructured, streamSchema *models.StreamSchema) error {
+		manifest, err := manifestYaml(stream)
+		if err != nil {
+			return err
+		}
+		edited, err := edit(manifest)
+		if err != nil {
+			return fmt.Errorf("failed to edit stream %s: %w", id, err)
+		}
+		if strings.TrimSpace(edited) == "" {
+			return fmt.Errorf("the edited manifest of stream %s is empty, the edit is cancelled", id)
+		}
+		value, err := schema.ParseYaml([]byte(edited))
+		if err != nil {
+			return fmt.Errorf("failed to read the edited manifest of stream %s: %w", id, err)
+		}
+		object, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("the edited manifest of stream %s is not an object", id)
+		}
+		stream.Object = object
+		return completeManifest(stream, streamSchema, namespace, id)
+	})
+}
+
+// update reads the stream resource, applies the change, validates the changed resource against the stream schema
+// and sends the difference as a JSON merge patch. The patch carries the resource version of the read resource,
+// so the changes made by others in the meantime are not overwritten. If requested, the stream is restarted afterwards.
+func (handler *SyncronousCommandHandler) update(ctx context.Context, id string, namespace string, streamClass string, options models.UpdateOptions, change changeFunc) (*models.OperationResult, error) {
+	clientApiSettings, streamClass, err := handler.discover(ctx, id, namespace, streamClass)
+	if err != nil {
+		return nil, err
+	}
+	live, err := handler.streamClassOperator.GetStream(ctx, id, namespace, clientApiSettings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stream %s: %w", id, err)
+	}
+	streamSchema, err := handler.schemaReader.GetStreamSchema(ctx, clientApiSettings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema of stream %s: %w", id, err)
+	}
+
+	original := editableObject(live)
+	changed := original.DeepCopy()
+	if err := change(changed, streamSchema); err != nil {
+		return nil, err
+	}
+	if err := handler.validateManifest(changed, streamSchema); err != nil {
+		return nil, err
+	}
+
+	patch, err := mergePatch(original, changed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build the patch of stream %s: %w", id, err)
+	}
+	if len(patch) == 0 {
+		handler.logger.Info("Nothing to change in the stream", "id", id)
+		return unchangedResult(id, namespace, "no changes"), nil
+	}
+	changes, err := streamDiff(id, original, changed)
+	if err != nil {
+		return nil, err
+	}
+	withResourceVersion(patch, live.GetResourceVersion())
+
+	patchResult, err := handler.streamClassOperator.Patch(ctx, id, namespace, clientApiSettings, patch, options.DryRun)
+	if err != nil {
+		handler.logger.Error("Failed to update stream", "id", id, "error", err)
+		return nil, fmt.Errorf("failed to update stream %s: %w", id, err)
+	}
+	result := newOperationResult(id, namespace, "updated", options.OperationOptions, patchResult)
+	// The server dry run previews the diff of the patched resource returned by the API server instead
+	if options.DryRun != models.DryRunServer {
+		result.Diff = changes
+	}
+	if !options.Restart || options.DryRun.IsDryRun() {
+		return result, nil
+	}
+	if options.RestartDeadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.RestartDeadline)
+		defer cancel()
+	}
+	return handler.restartUpdated(ctx, result, clientApiSettings, streamClass, options.OperationOptions)
+}
+
+// restartUpdated restarts the updated stream unless it is suspended, the operator starts the suspended stream
+// with the new spec when it is resumed.
+func (handler *SyncronousCommandHandler) restartUpdated(ctx context.Context, result *models.OperationResult, clientApiSettings *models.ClientApiSettings, streamClass string, options models.OperationOptions) (*models.OperationResult, error) {
+	status, err := handler.streamClassOperator.GetStatus(ctx, result.Name, result.Namespace, clientApiSettings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the state of stream %s: %w", result.Name, err)
+	}
+	if status.State == stateSuspended {
+		handler.logger.Info("Stream is suspended, skipping the restart", "id", result.Name)
+		result.Message = "the stream is suspended, the changes apply when it is resumed"
+		return result, nil
+	}
+
+	restarted, err := handler.Restart(ctx, result.Name, result.Namespace, streamClass, options)
+	if err != nil {
+		return nil, fmt.Errorf("stream %s was updated, but not restarted: %w", result.Name, err)
+	}
+	result.Operation = "updated and restarted"
+	result.Phase = restarted.Phase
+	return result, nil
+}
+
+// setFields converts the values to the field types of the stream schema and sets them in the stream spec.
+// It returns InvalidManifestError listing all fields that cannot be set.
+func setFields(stream *unstructured.Unstructured, streamSchema *models.StreamSchema, values []models.FieldValue) error {
+	var problems []string
+	for _, field := range values {
+		path := "spec." + field.Path
+		fieldSchema, err := schema.Lookup(streamSchema.Schema, path)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		value, err := schema.ParseValue(fieldSchema, field.Value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("field %s: %v", path, err))
+			continue
+		}
+		if err := schema.SetValue(stream.Object, path, value); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if len(problems) > 0 {
+		return &abstractions.InvalidManifestError{Name: stream.GetName(), Errors: problems}
+	}
+	return nil
+}
+
+// editableObject returns the copy of the object without the status and the metadata maintained by the API server.
+func editableObject(object *unstructured.Unstructured) *unstructured.Unstructured {
+	editable := object.DeepCopy()
+	delete(editable.Object, "status")
+	for _, field := range []string{"resourceVersion", "uid", "generation", "creationTimestamp", "managedFields", "selfLink"} {
+		unstructured.RemoveNestedField(editable.Object, "metadata", field)
+	}
+	annotations := editable.GetAnnotations()
+	if _, ok := annotations[models.LastAppliedAnnotation]; ok {
+		delete(annotations, models.LastAppliedAnnotation)
+		editable.SetAnnotations(annotations)
+	}
+	return editable
+}
+
+// mergePatch returns the JSON merge patch changing the original stream into the changed one, empty if they are equal.
+func mergePatch(original *unstructured.Unstructured, changed *unstructured.Unstructured) (map[string]any, error) {
+	originalJson, err := original.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	changedJson, err := changed.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	patchJson, err := jsonpatch.CreateMergePatch(originalJson, changedJson)
+	if err != nil {
+		return nil, err
+	}
+	patch := map[string]any{}
+	if err := utiljson.Unmarshal(patchJson, &patch); err != nil {
+		return nil, err
+	}
+	return patch, nil
+}
+
+// withResourceVersion adds the resource version of the read object to the patch,
+// so the patch is rejected if the object was changed in the meantime.
+func withResourceVersion(patch map[string]any, resourceVersion string) {
+	metadata, _ := patch["metadata"].(map[string]any)
+	if metadata == nil {
+		metadata = map[string]any{}
+		patch["metadata"] = metadata
+	}
+	metadata["resourceVersion"] = resourceVersion
+}
+
+// streamDiff returns the unified diff of the stream manifests.
+func streamDiff(id string, original *unstructured.Unstructured, changed *unstructured.Unstructured) (string, error) {
+	originalYaml, err := manifestYaml(original)
+	if err != nil {
+		return "", err
+	}
+	changedYaml, err := manifestYaml(changed)
+	if err != nil {
+		return "", err
+	}
+	return diff.Unified("live/"+id, "edited/"+id, originalYaml, changedYaml), nil
+}
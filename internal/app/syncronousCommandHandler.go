@@ -2,18 +2,27 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"sort"
 	"strings"
+	"time"
 )
 
-var NAMESPACE = "arcane"
-
 type SyncronousCommandHandler struct {
 	logger                *slog.Logger
 	apiSettingsDiscoverer abstractions.ApiSettingsDiscoverer
 	streamClassOperator   abstractions.StreamClassOperator
+	jobInspector          abstractions.JobInspector
+	jobLogReader          abstractions.JobLogReader
+	eventReader           abstractions.EventReader
+	referenceResolver     abstractions.ReferenceResolver
+	schemaReader          abstractions.SchemaReader
+	objectOperator        abstractions.ObjectOperator
 }
 
 var _ abstractions.StreamCommandHandler = (*SyncronousCommandHandler)(nil)
@@ -22,142 +31,469 @@ var _ abstractions.StreamCommandHandler = (*SyncronousCommandHandler)(nil)
 // This function is used to provide the handler in the dependency injection container.
 func ProvideStreamCommandHandler(logger *slog.Logger,
 	apiSettingsDiscoverer abstractions.ApiSettingsDiscoverer,
-	streamClassOperator abstractions.StreamClassOperator) (abstractions.StreamCommandHandler, error) {
+	streamClassOperator abstractions.StreamClassOperator,
+	jobInspector abstractions.JobInspector,
+	jobLogReader abstractions.JobLogReader,
+	eventReader abstractions.EventReader,
+	referenceResolver abstractions.ReferenceResolver,
+	schemaReader abstractions.SchemaReader,
+	objectOperator abstractions.ObjectOperator) (abstractions.StreamCommandHandler, error) {
 
 	handler := &SyncronousCommandHandler{
 		logger:                logger,
 		apiSettingsDiscoverer: apiSettingsDiscoverer,
 		streamClassOperator:   streamClassOperator,
+		jobInspector:          jobInspector,
+		jobLogReader:          jobLogReader,
+		eventReader:           eventReader,
+		referenceResolver:     referenceResolver,
+		schemaReader:          schemaReader,
+		objectOperator:        objectOperator,
 	}
 	return handler, nil
 }
 
-func (handler *SyncronousCommandHandler) Suspend(ctx context.Context, id string) error {
-	handler.logger.Info("Reading the client configuration")
-	clientApiSettings, err := handler.apiSettingsDiscoverer.DiscoveryFromJobs(ctx, id, NAMESPACE)
+func (handler *SyncronousCommandHandler) List(ctx context.Context, namespace string, filter models.StreamFilter) (*models.StreamList, error) {
+	handler.logger.Info("Listing streams", "namespace", namespace, "streamClass", filter.StreamClass, "selector", filter.LabelSelector)
+	streamClasses := []string{filter.StreamClass}
+	if filter.StreamClass == "" {
+		var err error
+		streamClasses, err = handler.apiSettingsDiscoverer.ListStreamClasses(ctx, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list stream classes: %w", err)
+		}
+	}
+
+	streams := make([]*models.StreamSummary, 0)
+	for _, streamClass := range streamClasses {
+		clientApiSettings, err := handler.apiSettingsDiscoverer.DiscoveryFromStreamClass(ctx, streamClass, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover stream class %s: %w", streamClass, err)
+		}
+		handler.logger.Debug("Discovered client API settings", "streamClass", streamClass, "settings", clientApiSettings)
+
+		classStreams, err := handler.streamClassOperator.List(ctx, namespace, filter.LabelSelector, clientApiSettings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list streams of stream class %s: %w", streamClass, err)
+		}
+		for _, stream := range classStreams {
+			stream.StreamClass = streamClass
+			streams = append(streams, stream)
+		}
+	}
+
+	sort.Slice(streams, func(i, j int) bool {
+		if streams[i].StreamClass != streams[j].StreamClass {
+			return streams[i].StreamClass < streams[j].StreamClass
+		}
+		return streams[i].Name < streams[j].Name
+	})
+	return &models.StreamList{Items: streams}, nil
+}
+
+func (handler *SyncronousCommandHandler) Status(ctx context.Context, id string, namespace string, streamClass string) (*models.StreamStatus, error) {
+	handler.logger.Info("Reading stream status", "id", id, "streamClass", streamClass)
+	clientApiSettings, streamClass, err := handler.discover(ctx, id, namespace, streamClass)
 	if err != nil {
-		return fmt.Errorf("failed to discover job %s: %w", id, err)
+		return nil, err
 	}
-	handler.logger.Debug("Discovered client API settings", "settings", clientApiSettings)
 
-	err = handler.streamClassOperator.Suspend(ctx, id, NAMESPACE, clientApiSettings)
+	status, err := handler.streamClassOperator.GetStatus(ctx, id, namespace, clientApiSettings)
 	if err != nil {
-		return fmt.Errorf("failed to suspend stream %s: %w", id, err)
+		return nil, fmt.Errorf("failed to get status of stream %s: %w", id, err)
 	}
 
-	return nil
+	// The status of the stream without a stream class shows no stream class
+	status.StreamClass, err = handler.requireStreamClass(ctx, id, namespace, clientApiSettings, streamClass)
+	if err != nil && !errors.Is(err, errNoStreamClass) {
+		return nil, err
+	}
+
+	status.Job, err = handler.jobInspector.GetJobStatus(ctx, id, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job of stream %s: %w", id, err)
+	}
+	return status, nil
 }
 
-func (handler *SyncronousCommandHandler) Resume(ctx context.Context, id string, streamClass string) error {
-	handler.logger.Info("Resuming stream", "id", id, "streamClass", streamClass)
-	clientApiSettings, err := handler.apiSettingsDiscoverer.DiscoveryFromStreamClass(ctx, streamClass, NAMESPACE)
+func (handler *SyncronousCommandHandler) Suspend(ctx context.Context, id string, namespace string, streamClass string, options models.OperationOptions) (*models.OperationResult, error) {
+	handler.logger.Info("Suspending stream", "id", id, "namespace", namespace, "dryRun", options.DryRun)
+	clientApiSettings, _, err := handler.discover(ctx, id, namespace, streamClass)
 	if err != nil {
-		return fmt.Errorf("failed to discover stream class%s: %w", id, err)
+		return nil, err
 	}
-	handler.logger.Debug("Discovered client API settings", "settings", clientApiSettings)
 
-	err = handler.streamClassOperator.Resume(ctx, id, NAMESPACE, clientApiSettings)
+	reason, err := handler.checkTransition(ctx, OperationSuspend, id, namespace, clientApiSettings, options)
 	if err != nil {
-		handler.logger.Error("Failed to resume stream", "id", id, "error", err)
-		return fmt.Errorf("failed to resume stream %s: %w", id, err)
+		return nil, err
+	}
+	if reason != "" {
+		return unchangedResult(id, namespace, reason), nil
 	}
 
-	return nil
+	patch, err := handler.streamClassOperator.Suspend(ctx, id, namespace, clientApiSettings, options.DryRun)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suspend stream %s: %w", id, err)
+	}
+
+	return newOperationResult(id, namespace, "suspended", options, patch), nil
 }
 
-func (handler *SyncronousCommandHandler) Backfill(ctx context.Context, id string, streamClass string, watch bool) error {
-	handler.logger.Info("Restarting the stream in backfill mode", "id", id, "wait", watch)
-	// TODO: handle situation when stream is not running
+func (handler *SyncronousCommandHandler) Resume(ctx context.Context, id string, namespace string, streamClass string, options models.OperationOptions) (*models.OperationResult, error) {
+	handler.logger.Info("Resuming stream", "id", id, "streamClass", streamClass, "dryRun", options.DryRun)
+	clientApiSettings, _, err := handler.discover(ctx, id, namespace, streamClass)
+	if err != nil {
+		return nil, err
+	}
 
-	clientApiSettings, err := handler.apiSettingsDiscoverer.DiscoveryFromJobs(ctx, id, NAMESPACE)
+	reason, err := handler.checkTransition(ctx, OperationResume, id, namespace, clientApiSettings, options)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			handler.logger.Warn("Job not found, probably the stream is suspended, trying to discover from stream class", "id", id)
-			if streamClass == "" {
-				return fmt.Errorf("stream class is required when the stream is suspended %s", id)
-			}
-			clientApiSettings, err = handler.apiSettingsDiscoverer.DiscoveryFromStreamClass(ctx, streamClass, NAMESPACE)
-			if err != nil {
-				return fmt.Errorf("failed to discover stream class %s: %w", streamClass, err)
-			}
-		} else {
-			return fmt.Errorf("failed to discover job %s: %w", id, err)
-		}
+		return nil, err
+	}
+	if reason != "" {
+		return unchangedResult(id, namespace, reason), nil
 	}
-	handler.logger.Debug("Discovered client API settings", "settings", clientApiSettings)
-	done := make(chan error, 1)
-	go func() {
-		defer close(done)
-		if watch {
-			done <- handler.streamClassOperator.WaitForStatus(ctx, abstractions.StreamPhaseBackfill, id, NAMESPACE, clientApiSettings)
-		} else {
-			done <- nil
-		}
-	}()
 
-	err = handler.streamClassOperator.Backfill(ctx, id, NAMESPACE, clientApiSettings)
+	patch, err := handler.streamClassOperator.Resume(ctx, id, namespace, clientApiSettings, options.DryRun)
 	if err != nil {
-		handler.logger.Error("Failed to backfill stream", "id", id, "error", err)
-		return fmt.Errorf("failed to backfill stream %s: %w", id, err)
+		handler.logger.Error("Failed to resume stream", "id", id, "error", err)
+		return nil, fmt.Errorf("failed to resume stream %s: %w", id, err)
 	}
 
-	waitErr := <-done
-	handler.logger.Info("Backfill operation completed", "id", id, "waitErr", waitErr)
-	if waitErr != nil {
-		handler.logger.Error("Failed to wait for stream to be backfilled", "id", id, "error", waitErr)
-		return fmt.Errorf("failed to wait for stream %s to be backfilled: %w", id, waitErr)
+	return newOperationResult(id, namespace, "resumed", options, patch), nil
+}
+
+func (handler *SyncronousCommandHandler) Backfill(ctx context.Context, id string, namespace string, streamClass string, options models.OperationOptions) (*models.OperationResult, error) {
+	handler.logger.Info("Restarting the stream in backfill mode", "id", id, "wait", options.Wait, "dryRun", options.DryRun)
+	clientApiSettings, _, err := handler.discover(ctx, id, namespace, streamClass)
+	if err != nil {
+		return nil, err
 	}
 
+	reason, err := handler.checkTransition(ctx, OperationBackfill, id, namespace, clientApiSettings, options)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		return unchangedResult(id, namespace, reason), nil
+	}
+
+	progress := newOperationProgress(ctx, id, namespace, "backfill", options)
+	patch, err := handler.streamClassOperator.Backfill(ctx, id, namespace, clientApiSettings, options.DryRun)
+	if err != nil {
+		handler.logger.Error("Failed to backfill stream", "id", id, "error", err)
+		return nil, fmt.Errorf("failed to backfill stream %s: %w", id, err)
+	}
+
+	watch := options.Wait && !options.DryRun.IsDryRun()
 	if watch {
+		// Reloading is transient: a short backfill can be over before the wait starts
+		err = handler.waitForStatus(ctx, abstractions.WaitCondition{
+			Phase:        abstractions.StreamPhaseBackfill,
+			Patch:        patch,
+			PassedPhases: []abstractions.StreamPhase{abstractions.StreamPhaseRunning},
+		}, id, namespace, clientApiSettings, progress)
+		if err != nil {
+			handler.logger.Error("Failed to wait for stream to be backfilled", "id", id, "error", err)
+			return nil, fmt.Errorf("failed to wait for stream %s to be backfilled: %w", id, err)
+		}
+
 		handler.logger.Info("Waiting for stream to complete backfill", "id", id)
-		err = handler.streamClassOperator.WaitForStatus(ctx, abstractions.StreamPhaseRunning, id, NAMESPACE, clientApiSettings)
+		err = handler.waitForStatus(ctx, abstractions.WaitCondition{Phase: abstractions.StreamPhaseRunning, Patch: patch}, id, namespace, clientApiSettings, progress)
 		if err != nil {
 			handler.logger.Error("Failed to wait for stream to be running", "id", id, "error", err)
-			return fmt.Errorf("failed to wait for stream %s to be running: %w", id, err)
+			return nil, fmt.Errorf("failed to wait for stream %s to be running: %w", id, err)
 		}
+		result := newOperationResult(id, namespace, "backfilled", options, patch)
+		result.Phase = abstractions.StreamPhaseRunning.String()
+		return result, nil
 	}
-	return nil
+	return newOperationResult(id, namespace, "backfill requested", options, patch), nil
 }
 
-func (handler *SyncronousCommandHandler) Restart(ctx context.Context, id string, wait bool) error {
-	handler.logger.Info("Restarting stream", "id", id, "wait", wait)
-	clientApiSettings, err := handler.apiSettingsDiscoverer.DiscoveryFromJobs(ctx, id, NAMESPACE)
+func (handler *SyncronousCommandHandler) Restart(ctx context.Context, id string, namespace string, streamClass string, options models.OperationOptions) (*models.OperationResult, error) {
+	handler.logger.Info("Restarting stream", "id", id, "wait", options.Wait, "dryRun", options.DryRun)
+	clientApiSettings, _, err := handler.discover(ctx, id, namespace, streamClass)
 	if err != nil {
-		return fmt.Errorf("failed to discover job %s: %w", id, err)
+		return nil, err
 	}
-	handler.logger.Debug("Discovered client API settings", "settings", clientApiSettings)
 
-	done := make(chan error, 1)
-	go func() {
-		defer close(done)
-		done <- handler.streamClassOperator.WaitForStatus(ctx, abstractions.StreamPhaseSuspended, id, NAMESPACE, clientApiSettings)
-	}()
+	reason, err := handler.checkTransition(ctx, OperationRestart, id, namespace, clientApiSettings, options)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		return unchangedResult(id, namespace, reason), nil
+	}
 
-	err = handler.streamClassOperator.Suspend(ctx, id, NAMESPACE, clientApiSettings)
+	progress := newOperationProgress(ctx, id, namespace, "restart", options)
+	suspendPatch, err := handler.streamClassOperator.Suspend(ctx, id, namespace, clientApiSettings, options.DryRun)
 	if err != nil {
 		handler.logger.Error("Failed to suspend stream", "id", id, "error", err)
-		return fmt.Errorf("failed to suspend stream %s: %w", id, err)
+		return nil, fmt.Errorf("failed to suspend stream %s: %w", id, err)
 	}
 
-	waitErr := <-done
-	if waitErr != nil {
-		handler.logger.Error("Failed to wait for stream to be suspended", "id", id, "error", waitErr)
-		return fmt.Errorf("failed to wait for stream %s to be suspended: %w", id, waitErr)
+	if !options.DryRun.IsDryRun() {
+		err = handler.waitForStatus(ctx, abstractions.WaitCondition{Phase: abstractions.StreamPhaseSuspended, Patch: suspendPatch}, id, namespace, clientApiSettings, progress)
+		if err != nil {
+			handler.logger.Error("Failed to wait for stream to be suspended", "id", id, "error", err)
+			return nil, fmt.Errorf("failed to wait for stream %s to be suspended: %w", id, err)
+		}
 	}
 
-	err = handler.streamClassOperator.Resume(ctx, id, NAMESPACE, clientApiSettings)
+	resumePatch, err := handler.streamClassOperator.Resume(ctx, id, namespace, clientApiSettings, options.DryRun)
 	if err != nil {
 		handler.logger.Error("Failed to resume stream", "id", id, "error", err)
-		return fmt.Errorf("failed to resume stream %s: %w", id, err)
+		return nil, fmt.Errorf("failed to resume stream %s: %w", id, err)
 	}
 
-	if wait {
-		err = handler.streamClassOperator.WaitForStatus(ctx, abstractions.StreamPhaseRunning, id, NAMESPACE, clientApiSettings)
+	if options.Wait && !options.DryRun.IsDryRun() {
+		err = handler.waitForStatus(ctx, abstractions.WaitCondition{Phase: abstractions.StreamPhaseRunning, Patch: resumePatch}, id, namespace, clientApiSettings, progress)
 		if err != nil {
 			handler.logger.Error("Failed to wait for stream to be running", "id", id, "error", err)
-			return fmt.Errorf("failed to wait for stream %s to be running: %w", id, err)
+			return nil, fmt.Errorf("failed to wait for stream %s to be running: %w", id, err)
 		}
+		result := newOperationResult(id, namespace, "restarted", options, suspendPatch, resumePatch)
+		result.Phase = abstractions.StreamPhaseRunning.String()
+		return result, nil
 	}
 
+	return newOperationResult(id, namespace, "restarted", options, suspendPatch, resumePatch), nil
+}
+
+func (handler *SyncronousCommandHandler) Logs(ctx context.Context, id string, namespace string, options models.LogOptions, writer io.Writer) error {
+	handler.logger.Info("Reading stream logs", "id", id, "follow", options.Follow)
+	if !options.Follow {
+		job, err := handler.jobInspector.GetJobStatus(ctx, id, namespace)
+		if err != nil {
+			return fmt.Errorf("failed to get job of stream %s: %w", id, err)
+		}
+		if job == nil {
+			return fmt.Errorf("job of stream %s not found, probably the stream is suspended", id)
+		}
+	}
+
+	err := handler.jobLogReader.ReadLogs(ctx, id, namespace, options, writer)
+	if err != nil {
+		return fmt.Errorf("failed to read logs of stream %s: %w", id, err)
+	}
 	return nil
 }
+
+func (handler *SyncronousCommandHandler) Events(ctx context.Context, id string, namespace string, streamClass string) (*models.StreamTimeline, error) {
+	handler.logger.Info("Reading stream events", "id", id, "streamClass", streamClass)
+	clientApiSettings, _, err := handler.discover(ctx, id, namespace, streamClass)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := handler.streamClassOperator.GetStatus(ctx, id, namespace, clientApiSettings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status of stream %s: %w", id, err)
+	}
+
+	entries, err := handler.eventReader.ListStreamEvents(ctx, id, namespace, status.Kind)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read events of stream %s: %w", id, err)
+	}
+
+	streamObject := strings.ToLower(status.Kind) + "/" + id
+	for _, condition := range status.Conditions {
+		transitionTime, err := time.Parse(time.RFC3339, condition.LastTransitionTime)
+		if err != nil {
+			handler.logger.Debug("Skipping condition without transition time", "id", id, "condition", condition.Type)
+			continue
+		}
+		entries = append(entries, models.TimelineEntry{
+			Time:    transitionTime.UTC(),
+			Type:    models.TimelineEntryCondition,
+			Object:  streamObject,
+			Reason:  fmt.Sprintf("%s=%s %s", condition.Type, condition.Status, condition.Reason),
+			Message: condition.Message,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+	return &models.StreamTimeline{Name: id, Namespace: namespace, Entries: entries}, nil
+}
+
+func (handler *SyncronousCommandHandler) Describe(ctx context.Context, id string, namespace string, streamClass string) (*models.StreamDescription, error) {
+	handler.logger.Info("Describing stream", "id", id, "streamClass", streamClass)
+	clientApiSettings, streamClass, err := handler.discover(ctx, id, namespace, streamClass)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := handler.streamClassOperator.GetStream(ctx, id, namespace, clientApiSettings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stream %s: %w", id, err)
+	}
+	description := models.DescriptionFromStreamObject(stream)
+
+	// The description reports the stream without a stream class as a problem of the stream class reference
+	streamClass, err = handler.requireStreamClass(ctx, id, namespace, clientApiSettings, streamClass)
+	if err != nil && !errors.Is(err, errNoStreamClass) {
+		return nil, err
+	}
+	description.StreamClass = streamClass
+	classReference := models.StreamReference{Kind: models.ReferenceKindStreamClass, Name: streamClass}
+	if streamClass == "" {
+		classReference.Problem = fmt.Sprintf("no stream class serves %s", clientApiSettings)
+	}
+	description.References = append([]models.StreamReference{classReference}, description.References...)
+
+	for i := range description.References {
+		reference := &description.References[i]
+		if reference.IsBroken() {
+			continue
+		}
+		if err := handler.referenceResolver.Resolve(ctx, reference, namespace); err != nil {
+			handler.logger.Warn("Failed to resolve stream reference", "id", id, "kind", reference.Kind, "name", reference.Name, "error", err)
+			reference.Problem = err.Error()
+		}
+	}
+	return description, nil
+}
+
+// findStreamClass returns the name of the stream class serving the stream resource, or an empty string if there is none.
+// errNoStreamClass reports that none of the stream classes in the namespace serves the resource of the stream.
+var errNoStreamClass = errors.New("no stream class serves")
+
+// requireStreamClass returns the stream class of the stream. The stream class is unknown if the stream
+// was discovered from its job, then it is found by the API settings of the stream.
+func (handler *SyncronousCommandHandler) requireStreamClass(ctx context.Context, id string, namespace string, clientApiSettings *models.ClientApiSettings, streamClass string) (string, error) {
+	if streamClass != "" {
+		return streamClass, nil
+	}
+	streamClass, err := handler.findStreamClass(ctx, namespace, clientApiSettings)
+	if err != nil {
+		return "", err
+	}
+	if streamClass == "" {
+		return "", fmt.Errorf("%w %s of stream %s", errNoStreamClass, clientApiSettings, id)
+	}
+	return streamClass, nil
+}
+
+func (handler *SyncronousCommandHandler) findStreamClass(ctx context.Context, namespace string, clientApiSettings *models.ClientApiSettings) (string, error) {
+	streamClasses, err := handler.apiSettingsDiscoverer.ListStreamClasses(ctx, namespace)
+	if err != nil {
+		return "", fmt.Errorf("failed to list stream classes: %w", err)
+	}
+	for _, streamClass := range streamClasses {
+		classSettings, err := handler.apiSettingsDiscoverer.DiscoveryFromStreamClass(ctx, streamClass, namespace)
+		if err != nil {
+			handler.logger.Debug("Skipping stream class", "streamClass", streamClass, "error", err)
+			continue
+		}
+		if classSettings.ToGroupVersionResource() == clientApiSettings.ToGroupVersionResource() {
+			return streamClass, nil
+		}
+	}
+	return "", nil
+}
+
+// waitForStatus waits for the stream to reach the phase, reporting the progress if it is requested.
+// If the stream fails instead, the failure reason of the stream job is added to the returned StreamFailedError.
+// If the deadline is exceeded, it returns WaitTimeoutError.
+func (handler *SyncronousCommandHandler) waitForStatus(ctx context.Context, condition abstractions.WaitCondition, id string, namespace string, clientApiSettings *models.ClientApiSettings, progress *operationProgress) error {
+	stopProgress := handler.watchProgress(ctx, progress, condition.Phase, clientApiSettings)
+	err := handler.streamClassOperator.WaitForStatus(ctx, condition, id, namespace, clientApiSettings)
+	stopProgress()
+	var failed *abstractions.StreamFailedError
+	if !errors.As(err, &failed) {
+		return timeoutError(err, &abstractions.WaitTimeoutError{Id: id, Phase: condition.Phase.String()})
+	}
+
+	job, jobErr := handler.jobInspector.GetJobStatus(ctx, id, namespace)
+	if jobErr != nil {
+		handler.logger.Warn("Failed to read the job of the failed stream", "id", id, "error", jobErr)
+		return err
+	}
+	if job != nil {
+		failed.JobFailureReason = job.FailureReason
+	}
+	return err
+}
+
+// timeoutError returns the WaitTimeoutError wrapping the error if the deadline of the wait was exceeded,
+// the other errors are returned unchanged.
+func timeoutError(err error, timeout *abstractions.WaitTimeoutError) error {
+	if !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	timeout.Err = err
+	return timeout
+}
+
+// checkTransition reads the current stream state and validates the operation against it.
+// It returns the reason to skip the operation if the stream is already in the requested state,
+// or an InvalidTransitionError if the operation is not valid. Both are ignored when the operation is forced.
+func (handler *SyncronousCommandHandler) checkTransition(ctx context.Context, operation StreamOperationKind, id string, namespace string, clientApiSettings *models.ClientApiSettings, options models.OperationOptions) (string, error) {
+	status, err := handler.streamClassOperator.GetStatus(ctx, id, namespace, clientApiSettings)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the state of stream %s: %w", id, err)
+	}
+
+	transition := ValidateTransition(operation, status.Phase, status.State)
+	handler.logger.Debug("Validated stream transition", "id", id, "operation", operation, "phase", status.Phase, "state", status.State, "decision", transition.Decision)
+	if transition.Decision == TransitionAllowed {
+		return "", nil
+	}
+	if options.Force {
+		handler.logger.Warn("Forcing the operation", "id", id, "operation", operation, "reason", transition.Reason)
+		return "", nil
+	}
+	if transition.Decision == TransitionNoOp {
+		handler.logger.Info("Nothing to do for the stream", "id", id, "operation", operation, "reason", transition.Reason)
+		return transition.Reason, nil
+	}
+	return "", &InvalidTransitionError{Operation: operation, Id: id, Reason: transition.Reason}
+}
+
+// unchangedResult creates the result of the operation skipped for the reason.
+func unchangedResult(id string, namespace string, reason string) *models.OperationResult {
+	result := models.NewOperationResult(id, namespace, "unchanged")
+	result.Message = reason
+	return result
+}
+
+// newOperationResult creates the operation result, the patches are included only in the dry run mode.
+func newOperationResult(id string, namespace string, operation string, options models.OperationOptions, patches ...*models.PatchResult) *models.OperationResult {
+	result := models.NewOperationResult(id, namespace, operation)
+	if options.DryRun.IsDryRun() {
+		result.Patches = patches
+	}
+	return result
+}
+
+// discover resolves the API settings of the stream from its job.
+// If the job does not exist, the stream is probably suspended and the settings are read from the stream class,
+// or, if the stream class is not provided, from the stream class owning a stream with this ID.
+// It returns the API settings and the stream class if it is known.
+func (handler *SyncronousCommandHandler) discover(ctx context.Context, id string, namespace string, streamClass string) (*models.ClientApiSettings, string, error) {
+	clientApiSettings, err := handler.apiSettingsDiscoverer.DiscoveryFromJobs(ctx, id, namespace)
+	if err != nil {
+		if !errors.Is(err, abstractions.ErrJobNotFound) {
+			return nil, "", fmt.Errorf("failed to discover job %s: %w", id, err)
+		}
+		if streamClass == "" {
+			handler.logger.Warn("Job not found, probably the stream is suspended, searching the stream in all stream classes", "id", id)
+			clientApiSettings, streamClass, err = handler.apiSettingsDiscoverer.DiscoveryFromStreamId(ctx, id, namespace)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to discover stream class of stream %s: %w", id, err)
+			}
+			handler.logger.Info("Discovered stream class", "id", id, "streamClass", streamClass)
+		} else {
+			handler.logger.Warn("Job not found, probably the stream is suspended, trying to discover from stream class", "id", id)
+			clientApiSettings, err = handler.apiSettingsDiscoverer.DiscoveryFromStreamClass(ctx, streamClass, namespace)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to discover stream class %s: %w", streamClass, err)
+			}
+		}
+	}
+	handler.logger.Debug("Discovered client API settings", "settings", clientApiSettings)
+	return clientApiSettings, streamClass, nil
+}
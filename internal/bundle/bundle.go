@@ -0,0 +1,134 @@
+// Package bundle reads and writes the stream bundles as multi-document yaml files or directories of yaml files.
+package bundle
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"s-vitaliy/kubectl-plugin-arcane/internal/schema"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// Write writes the objects of the bundle as the yaml documents separated by ---.
+func Write(writer io.Writer, bundle *models.StreamBundle) error {
+	for i, object := range bundle.Objects {
+		if i > 0 {
+			if _, err := fmt.Fprintln(writer, "---"); err != nil {
+				return err
+			}
+		}
+		data, err := yaml.Marshal(object.Object)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s %s: %w", object.GetKind(), object.GetName(), err)
+		}
+		if _, err := writer.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteDir writes every object of the bundle to its own file in the directory, named after the object kind and name.
+// It returns the paths of the written files.
+func WriteDir(dir string, bundle *models.StreamBundle) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create bundle directory %s: %w", dir, err)
+	}
+	paths := make([]string, 0, len(bundle.Objects))
+	for _, object := range bundle.Objects {
+		path := filepath.Join(dir, fmt.Sprintf("%s-%s.yaml", strings.ToLower(object.GetKind()), object.GetName()))
+		var data bytes.Buffer
+		if err := Write(&data, &models.StreamBundle{Objects: []*unstructured.Unstructured{object}}); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, data.Bytes(), 0o644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// Read reads the bundle from the multi-document yaml file or from the yaml and json files of the directory,
+// - reads the standard input.
+func Read(path string) (*models.StreamBundle, error) {
+	if path == "-" {
+		return read(os.Stdin, path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle %s: %w", path, err)
+	}
+	if !info.IsDir() {
+		return readFile(path)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle %s: %w", path, err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	bundle := &models.StreamBundle{}
+	for _, entry := range entries {
+		extension := filepath.Ext(entry.Name())
+		if entry.IsDir() || (extension != ".yaml" && extension != ".yml" && extension != ".json") {
+			continue
+		}
+		file, err := readFile(filepath.Join(path, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		bundle.Objects = append(bundle.Objects, file.Objects...)
+	}
+	return bundle, nil
+}
+
+func readFile(path string) (*models.StreamBundle, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle %s: %w", path, err)
+	}
+	defer file.Close()
+	return read(file, path)
+}
+
+// read splits the documents of the reader, the empty documents are skipped.
+func read(reader io.Reader, path string) (*models.StreamBundle, error) {
+	documents := utilyaml.NewYAMLReader(bufio.NewReader(reader))
+	bundle := &models.StreamBundle{}
+	for {
+		data, err := documents.Read()
+		if errors.Is(err, io.EOF) {
+			return bundle, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle %s: %w", path, err)
+		}
+		value, err := schema.ParseYaml(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle %s: %w", path, err)
+		}
+		if value == nil {
+			continue
+		}
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("bundle %s contains a document that is not an object", path)
+		}
+		item := &unstructured.Unstructured{Object: object}
+		if item.GetKind() == "" || item.GetName() == "" {
+			return nil, fmt.Errorf("bundle %s contains an object without the kind or the name", path)
+		}
+		bundle.Objects = append(bundle.Objects, item)
+	}
+}
@@ -0,0 +1,93 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+type eventService struct {
+	logger *slog.Logger
+	client kubernetes.Interface
+}
+
+var _ abstractions.EventReader = &eventService{}
+
+// ProvideEventService provides a new instance of eventService.
+func ProvideEventService(logger *slog.Logger, client kubernetes.Interface) abstractions.EventReader {
+	return &eventService{logger: logger, client: client}
+}
+
+// ListStreamEvents implements abstractions.EventReader.
+// The stream custom resource and its job share the stream ID as the name, the job pods are named <id>-<suffix>,
+// so the events of the pods of previous job generations are found even if the pods are already deleted.
+// The events of other objects sharing the name, e.g. a config map, are skipped.
+func (s *eventService) ListStreamEvents(ctx context.Context, id string, namespace string, streamKind string) ([]models.TimelineEntry, error) {
+	named, err := s.listEvents(ctx, namespace, fields.OneTermEqualSelector("involvedObject.name", id))
+	if err != nil {
+		return nil, err
+	}
+	pods, err := s.listEvents(ctx, namespace, fields.OneTermEqualSelector("involvedObject.kind", "Pod"))
+	if err != nil {
+		return nil, err
+	}
+
+	podName := regexp.MustCompile("^" + regexp.QuoteMeta(id) + "-[a-z0-9]{5}$")
+	entries := make([]models.TimelineEntry, 0)
+	for _, event := range named {
+		if kind := event.InvolvedObject.Kind; event.InvolvedObject.Name == id && (kind == streamKind || kind == "Job") {
+			entries = append(entries, timelineEntry(event))
+		}
+	}
+	for _, event := range pods {
+		if event.InvolvedObject.Kind == "Pod" && podName.MatchString(event.InvolvedObject.Name) {
+			entries = append(entries, timelineEntry(event))
+		}
+	}
+	s.logger.Debug("Found stream events", "id", id, "count", len(entries))
+	return entries, nil
+}
+
+// listEvents lists the events of the namespace matching the field selector.
+func (s *eventService) listEvents(ctx context.Context, namespace string, selector fields.Selector) ([]corev1.Event, error) {
+	events, err := s.client.CoreV1().Events(namespace).List(ctx, v1.ListOptions{FieldSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events in namespace %s: %w", namespace, err)
+	}
+	return events.Items, nil
+}
+
+// timelineEntry converts the event to the entry of the stream timeline.
+func timelineEntry(event corev1.Event) models.TimelineEntry {
+	return models.TimelineEntry{
+		Time:    eventTime(event).UTC(),
+		Type:    event.Type,
+		Object:  strings.ToLower(event.InvolvedObject.Kind) + "/" + event.InvolvedObject.Name,
+		Reason:  event.Reason,
+		Message: strings.TrimSpace(event.Message),
+		Count:   event.Count,
+	}
+}
+
+// eventTime returns the time the event was last observed.
+func eventTime(event corev1.Event) v1.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp
+	case !event.EventTime.IsZero():
+		return v1.NewTime(event.EventTime.Time)
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp
+	default:
+		return event.CreationTimestamp
+	}
+}
@@ -0,0 +1,89 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+)
+
+var podResourceRef = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+
+type jobInspectionService struct {
+	logger           *slog.Logger
+	dynamicInterface dynamic.Interface
+}
+
+var _ abstractions.JobInspector = &jobInspectionService{}
+
+// ProvideJobInspectionService provides a new instance of jobInspectionService.
+func ProvideJobInspectionService(logger *slog.Logger, dynamicInterface dynamic.Interface) abstractions.JobInspector {
+	return &jobInspectionService{logger: logger, dynamicInterface: dynamicInterface}
+}
+
+// GetJobStatus implements abstractions.JobInspector.
+func (s *jobInspectionService) GetJobStatus(ctx context.Context, jobName string, namespace string) (*models.JobStatus, error) {
+	job, err := s.dynamicInterface.Resource(jobResourceRef).Namespace(namespace).Get(ctx, jobName, v1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		s.logger.Debug("Job not found", "namespace", namespace, "job", jobName)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job %s: %w", jobName, err)
+	}
+	status := models.FromJobObject(job)
+
+	pods, err := s.dynamicInterface.Resource(podResourceRef).Namespace(namespace).List(ctx, v1.ListOptions{
+		LabelSelector: fmt.Sprintf("job-name=%s", jobName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods of job %s: %w", jobName, err)
+	}
+	for i := range pods.Items {
+		status.Pods = append(status.Pods, models.FromPodObject(&pods.Items[i]))
+	}
+	return status, nil
+}
+
+// WaitForJobDeletion implements abstractions.JobInspector.
+func (s *jobInspectionService) WaitForJobDeletion(ctx context.Context, jobName string, namespace string) error {
+	s.logger.Info("Waiting for job deletion", "namespace", namespace, "job", jobName)
+	dynamicClient := s.dynamicInterface.Resource(jobResourceRef).Namespace(namespace)
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", jobName).String()
+	listWatch := &cache.ListWatch{
+		ListWithContextFunc: func(ctx context.Context, options v1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			return dynamicClient.List(ctx, options)
+		},
+		WatchFuncWithContext: func(ctx context.Context, options v1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return dynamicClient.Watch(ctx, options)
+		},
+	}
+	_, err := watchtools.UntilWithSync(ctx, listWatch, &unstructured.Unstructured{}, func(store cache.Store) (bool, error) {
+		_, exists, err := store.GetByKey(namespace + "/" + jobName)
+		return !exists, err
+	}, func(event watch.Event) (bool, error) {
+		job, ok := event.Object.(*unstructured.Unstructured)
+		return ok && event.Type == watch.Deleted && job.GetName() == jobName, nil
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("context cancelled while waiting for job %s deletion: %w", jobName, ctx.Err())
+		}
+		return fmt.Errorf("failed to wait for job %s deletion: %w", jobName, err)
+	}
+	return nil
+}
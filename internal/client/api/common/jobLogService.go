@@ -0,0 +1,187 @@
+package common
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// podPollInterval is the interval of checking for new pods and container restarts in the follow mode.
+const podPollInterval = 2 * time.Second
+
+type jobLogService struct {
+	logger *slog.Logger
+	client kubernetes.Interface
+}
+
+var _ abstractions.JobLogReader = &jobLogService{}
+
+// ProvideJobLogService provides a new instance of jobLogService.
+func ProvideJobLogService(logger *slog.Logger, client kubernetes.Interface) abstractions.JobLogReader {
+	return &jobLogService{logger: logger, client: client}
+}
+
+// ReadLogs implements abstractions.JobLogReader.
+func (s *jobLogService) ReadLogs(ctx context.Context, jobName string, namespace string, options models.LogOptions, writer io.Writer) error {
+	output := &prefixedWriter{writer: writer}
+	if !options.Follow || options.Previous {
+		pods, err := s.listPods(ctx, jobName, namespace)
+		if err != nil {
+			return err
+		}
+		if len(pods) == 0 {
+			return fmt.Errorf("no pods found for job %s", jobName)
+		}
+		for _, pod := range pods {
+			for _, container := range s.containers(pod, options) {
+				if err := s.copyLogs(ctx, pod, container, namespace, options, output); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return s.follow(ctx, jobName, namespace, options, output)
+}
+
+// follow streams the logs of every container instance of the job pods until the context is cancelled.
+// A container instance is identified by the pod UID, the container name and the restart count,
+// so the logs are streamed again after a container restart, for new pods and for new job generations.
+func (s *jobLogService) follow(ctx context.Context, jobName string, namespace string, options models.LogOptions, output *prefixedWriter) error {
+	streamed := map[string]bool{}
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	ticker := time.NewTicker(podPollInterval)
+	defer ticker.Stop()
+	for {
+		pods, err := s.listPods(ctx, jobName, namespace)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		for _, pod := range pods {
+			for _, container := range s.containers(pod, options) {
+				key, ready := containerInstance(pod, container)
+				if !ready || streamed[key] {
+					continue
+				}
+				streamed[key] = true
+				s.logger.Debug("Streaming container logs", "pod", pod.Name, "container", container)
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if err := s.copyLogs(ctx, pod, container, namespace, options, output); err != nil && ctx.Err() == nil {
+						s.logger.Warn("Failed to stream container logs", "pod", pod.Name, "container", container, "error", err)
+					}
+				}()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *jobLogService) listPods(ctx context.Context, jobName string, namespace string) ([]corev1.Pod, error) {
+	pods, err := s.client.CoreV1().Pods(namespace).List(ctx, v1.ListOptions{
+		LabelSelector: fmt.Sprintf("job-name=%s", jobName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods of job %s: %w", jobName, err)
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].CreationTimestamp.Before(&pods.Items[j].CreationTimestamp)
+	})
+	return pods.Items, nil
+}
+
+func (s *jobLogService) containers(pod corev1.Pod, options models.LogOptions) []string {
+	names := make([]string, 0, len(pod.Spec.Containers))
+	for _, container := range pod.Spec.Containers {
+		names = append(names, container.Name)
+		if !options.AllContainers {
+			break
+		}
+	}
+	return names
+}
+
+func (s *jobLogService) copyLogs(ctx context.Context, pod corev1.Pod, container string, namespace string, options models.LogOptions, output *prefixedWriter) error {
+	logOptions := &corev1.PodLogOptions{
+		Container: container,
+		Follow:    options.Follow && !options.Previous,
+		Previous:  options.Previous,
+	}
+	if options.Since > 0 {
+		seconds := int64(options.Since.Seconds())
+		logOptions.SinceSeconds = &seconds
+	}
+	if options.TailLines > 0 {
+		logOptions.TailLines = &options.TailLines
+	}
+
+	stream, err := s.client.CoreV1().Pods(namespace).GetLogs(pod.Name, logOptions).Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read logs of pod %s container %s: %w", pod.Name, container, err)
+	}
+	defer stream.Close()
+
+	prefix := fmt.Sprintf("[pod/%s]", pod.Name)
+	if options.AllContainers {
+		prefix = fmt.Sprintf("[pod/%s/%s]", pod.Name, container)
+	}
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if err := output.writeLine(prefix, scanner.Text()); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to read logs of pod %s container %s: %w", pod.Name, container, err)
+	}
+	return nil
+}
+
+// containerInstance returns the key of the current container instance
+// and whether the container has started, so its logs can be read.
+func containerInstance(pod corev1.Pod, container string) (string, bool) {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != container {
+			continue
+		}
+		started := status.State.Running != nil || status.State.Terminated != nil
+		return fmt.Sprintf("%s/%s/%d", pod.UID, container, status.RestartCount), started
+	}
+	return "", false
+}
+
+// prefixedWriter writes whole lines from several goroutines without interleaving them.
+type prefixedWriter struct {
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+func (w *prefixedWriter) writeLine(prefix string, line string) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	_, err := fmt.Fprintf(w.writer, "%s %s\n", prefix, line)
+	return err
+}
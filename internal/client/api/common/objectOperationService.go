@@ -0,0 +1,115 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+type objectOperationService struct {
+	logger           *slog.Logger
+	dynamicInterface dynamic.Interface
+}
+
+var _ abstractions.ObjectOperator = &objectOperationService{}
+
+// ProvideObjectOperationService provides a new instance of objectOperationService.
+func ProvideObjectOperationService(logger *slog.Logger, dynamicInterface dynamic.Interface) abstractions.ObjectOperator {
+	return &objectOperationService{logger: logger, dynamicInterface: dynamicInterface}
+}
+
+// Get implements abstractions.ObjectOperator.
+func (s *objectOperationService) Get(ctx context.Context, kind string, name string, namespace string) (*unstructured.Unstructured, error) {
+	client, err := s.resourceClient(kind, namespace)
+	if err != nil {
+		return nil, err
+	}
+	object, err := client.Get(ctx, name, v1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		s.logger.Debug("Object not found", "namespace", namespace, "kind", kind, "name", name)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s %s: %w", kind, name, err)
+	}
+	return object, nil
+}
+
+// Create implements abstractions.ObjectOperator.
+func (s *objectOperationService) Create(ctx context.Context, kind string, object *unstructured.Unstructured, namespace string, dryRun models.DryRunMode) error {
+	s.logger.Debug("Creating object", "kind", kind, "name", object.GetName(), "namespace", namespace, "dryRun", dryRun)
+	client, err := s.resourceClient(kind, namespace)
+	if err != nil {
+		return err
+	}
+	if dryRun == models.DryRunClient {
+		s.logger.Info("Object is not created in the client dry run mode", "kind", kind, "name", object.GetName())
+		return nil
+	}
+	options := v1.CreateOptions{}
+	if dryRun == models.DryRunServer {
+		options.DryRun = []string{v1.DryRunAll}
+	}
+	if _, err := client.Create(ctx, object, options); err != nil {
+		s.logger.Error("Failed to create object", "kind", kind, "name", object.GetName(), "error", err)
+		return fmt.Errorf("failed to create %s %s: %w", kind, object.GetName(), err)
+	}
+	s.logger.Info("Object created successfully", "kind", kind, "name", object.GetName(), "dryRun", dryRun)
+	return nil
+}
+
+// Patch implements abstractions.ObjectOperator.
+func (s *objectOperationService) Patch(ctx context.Context, kind string, name string, namespace string, patch map[string]any, dryRun models.DryRunMode) (*models.PatchResult, error) {
+	s.logger.Debug("Patching object", "kind", kind, "name", name, "namespace", namespace, "dryRun", dryRun)
+	client, err := s.resourceClient(kind, namespace)
+	if err != nil {
+		return nil, err
+	}
+	resource, _ := referenceResource(kind)
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal patch: %w", err)
+	}
+	result := &models.PatchResult{Resource: resource.GroupResource().String(), Name: name, Patch: string(patchBytes)}
+	if dryRun == models.DryRunClient {
+		result.DryRun = dryRun
+		s.logger.Info("Object patch is not sent in the client dry run mode", "kind", kind, "name", name)
+		return result, nil
+	}
+
+	options := v1.PatchOptions{}
+	if dryRun == models.DryRunServer {
+		result.DryRun = dryRun
+		options.DryRun = []string{v1.DryRunAll}
+	}
+	patched, err := client.Patch(ctx, name, types.MergePatchType, patchBytes, options)
+	if err != nil {
+		s.logger.Error("Failed to patch object", "kind", kind, "name", name, "error", err)
+		return nil, fmt.Errorf("failed to patch %s %s: %w", kind, name, err)
+	}
+	result.ResourceVersion = patched.GetResourceVersion()
+	s.logger.Info("Object patched successfully", "kind", kind, "name", name, "resourceVersion", result.ResourceVersion)
+	return result, nil
+}
+
+// resourceClient returns the client of the resource of the object kind in the namespace.
+// The secrets are rejected, so their values are never read or written.
+func (s *objectOperationService) resourceClient(kind string, namespace string) (dynamic.ResourceInterface, error) {
+	if kind == models.ReferenceKindSecret {
+		return nil, fmt.Errorf("the %s objects are not supported", kind)
+	}
+	resource, err := referenceResource(kind)
+	if err != nil {
+		return nil, err
+	}
+	return s.dynamicInterface.Resource(resource).Namespace(namespace), nil
+}
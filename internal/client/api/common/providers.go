@@ -5,11 +5,15 @@ import (
 	"log/slog"
 
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
 
 type ConfigReader interface {
 	ReadConfig() (*rest.Config, error)
+
+	// ReadNamespace returns the namespace the commands should operate in.
+	ReadNamespace() (string, error)
 }
 
 func ProvideDynamicClient(configReader ConfigReader, logger *slog.Logger) (dynamic.Interface, error) {
@@ -26,3 +30,17 @@ func ProvideDynamicClient(configReader ConfigReader, logger *slog.Logger) (dynam
 	logger.Debug("Created dynamic client", "clientset", clientset)
 	return clientset, nil
 }
+
+func ProvideKubernetesClient(configReader ConfigReader, logger *slog.Logger) (kubernetes.Interface, error) {
+	config, err := configReader.ReadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	logger.Info("Creating kubernetes client")
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return clientset, nil
+}
@@ -0,0 +1,86 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"slices"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var secretResourceRef = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "secrets"}
+
+var jobTemplateResourceRef = schema.GroupVersionResource{
+	Group:    "streaming.sneaksanddata.com",
+	Version:  "v1",
+	Resource: "streaming-job-templates",
+}
+
+type referenceResolutionService struct {
+	logger           *slog.Logger
+	dynamicInterface dynamic.Interface
+}
+
+var _ abstractions.ReferenceResolver = &referenceResolutionService{}
+
+// ProvideReferenceResolutionService provides a new instance of referenceResolutionService.
+func ProvideReferenceResolutionService(logger *slog.Logger, dynamicInterface dynamic.Interface) abstractions.ReferenceResolver {
+	return &referenceResolutionService{logger: logger, dynamicInterface: dynamicInterface}
+}
+
+// Resolve implements abstractions.ReferenceResolver.
+func (s *referenceResolutionService) Resolve(ctx context.Context, reference *models.StreamReference, namespace string) error {
+	if reference.Name == "" {
+		reference.Problem = "the referenced name is not set"
+		return nil
+	}
+
+	resource, err := referenceResource(reference.Kind)
+	if err != nil {
+		return err
+	}
+
+	object, err := s.dynamicInterface.Resource(resource).Namespace(namespace).Get(ctx, reference.Name, v1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		s.logger.Debug("Referenced object not found", "namespace", namespace, "kind", reference.Kind, "name", reference.Name)
+		reference.Problem = fmt.Sprintf("%s %s not found", reference.Kind, reference.Name)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get %s %s: %w", reference.Kind, reference.Name, err)
+	}
+
+	if reference.Kind == models.ReferenceKindSecret {
+		// Only the keys are kept, the secret values must never leave this function
+		data, _ := object.Object["data"].(map[string]any)
+		reference.Keys = make([]string, 0, len(data))
+		for key := range data {
+			reference.Keys = append(reference.Keys, key)
+		}
+		slices.Sort(reference.Keys)
+		if reference.Key != "" && !slices.Contains(reference.Keys, reference.Key) {
+			reference.Problem = fmt.Sprintf("key %s not found in Secret %s", reference.Key, reference.Name)
+		}
+	}
+	return nil
+}
+
+// referenceResource returns the resource of the referenced object kind.
+func referenceResource(kind string) (schema.GroupVersionResource, error) {
+	switch kind {
+	case models.ReferenceKindSecret:
+		return secretResourceRef, nil
+	case models.ReferenceKindJobTemplate:
+		return jobTemplateResourceRef, nil
+	case models.ReferenceKindStreamClass:
+		return streamClassResourceRef, nil
+	default:
+		return schema.GroupVersionResource{}, fmt.Errorf("unsupported reference kind %s", kind)
+	}
+}
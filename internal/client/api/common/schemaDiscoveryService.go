@@ -0,0 +1,58 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var crdResourceRef = schema.GroupVersionResource{
+	Group:    "apiextensions.k8s.io",
+	Version:  "v1",
+	Resource: "customresourcedefinitions",
+}
+
+type schemaDiscoveryService struct {
+	logger           *slog.Logger
+	dynamicInterface dynamic.Interface
+}
+
+var _ abstractions.SchemaReader = &schemaDiscoveryService{}
+
+// ProvideSchemaDiscoveryService provides a new instance of schemaDiscoveryService.
+func ProvideSchemaDiscoveryService(logger *slog.Logger, dynamicInterface dynamic.Interface) abstractions.SchemaReader {
+	return &schemaDiscoveryService{logger: logger, dynamicInterface: dynamicInterface}
+}
+
+// GetStreamSchema implements abstractions.SchemaReader.
+func (s *schemaDiscoveryService) GetStreamSchema(ctx context.Context, apiSettings *models.ClientApiSettings) (*models.StreamSchema, error) {
+	gvr := apiSettings.ToGroupVersionResource()
+	name := gvr.Resource + "." + gvr.Group
+	crd, err := s.dynamicInterface.Resource(crdResourceRef).Get(ctx, name, v1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custom resource definition %s: %w", name, err)
+	}
+
+	kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, value := range versions {
+		version, ok := value.(map[string]any)
+		if !ok || version["name"] != gvr.Version {
+			continue
+		}
+		openApiSchema, found, err := unstructured.NestedMap(version, "schema", "openAPIV3Schema")
+		if err != nil || !found {
+			return nil, fmt.Errorf("custom resource definition %s has no schema for version %s", name, gvr.Version)
+		}
+		s.logger.Debug("Read stream schema", "crd", name, "version", gvr.Version, "kind", kind)
+		return &models.StreamSchema{ApiVersion: gvr.GroupVersion().String(), Kind: kind, Schema: openApiSchema}, nil
+	}
+	return nil, fmt.Errorf("custom resource definition %s does not serve version %s", name, gvr.Version)
+}
@@ -7,11 +7,20 @@ import (
 	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
 	"s-vitaliy/kubectl-plugin-arcane/internal/models"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 )
 
+var jobResourceRef = schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}
+
+var streamClassResourceRef = schema.GroupVersionResource{
+	Group:    "streaming.sneaksanddata.com",
+	Version:  "v1beta1",
+	Resource: "stream-classes",
+}
+
 type streamClassDiscoveryService struct {
 	logger           *slog.Logger
 	dynamicInterface dynamic.Interface
@@ -29,10 +38,13 @@ func ProvideStreamClassDiscoveryService(logger *slog.Logger, dynamicInterface dy
 
 // DiscoveryFromJobs discovers API settings from a job.
 func (s *streamClassDiscoveryService) DiscoveryFromJobs(ctx context.Context, jobstreamClass string, namespace string) (*models.ClientApiSettings, error) {
-	jobResourceRef := schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}
 	dynamicClient := s.dynamicInterface.Resource(jobResourceRef).Namespace(namespace)
 
 	jobValue, err := dynamicClient.Get(ctx, jobstreamClass, v1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		s.logger.Debug("Job not found", "namespace", namespace, "job", jobstreamClass)
+		return nil, fmt.Errorf("%w: %s", abstractions.ErrJobNotFound, jobstreamClass)
+	}
 	if err != nil {
 		s.logger.Error("Failed to get job", "namespace", "streamClass", namespace, jobstreamClass, "error", err)
 		return nil, fmt.Errorf("failed to get job %s: %w", jobstreamClass, err)
@@ -47,23 +59,24 @@ func (s *streamClassDiscoveryService) DiscoveryFromJobs(ctx context.Context, job
 	annotations, ok := metadata["annotations"].(map[string]any)
 	if !ok {
 		s.logger.Error("Failed to get annotations from job metadata", "namespace", "streamClass", namespace, jobstreamClass)
-		return nil, fmt.Errorf("failed to get annotations from job %s metadata", jobstreamClass)
+		return nil, fmt.Errorf("%w: failed to get annotations from job %s metadata", abstractions.ErrAnnotationMissing, jobstreamClass)
 	}
 
 	s.logger.Debug("Annotations from job", "namespace", "streamClass", namespace, jobstreamClass, "annotations", annotations)
-	return models.FromJobAnnotations(annotations)
+	settings, err := models.FromJobAnnotations(annotations)
+	if err != nil {
+		return nil, fmt.Errorf("%w: job %s: %w", abstractions.ErrAnnotationMissing, jobstreamClass, err)
+	}
+	return settings, nil
 }
 
 // DiscoveryFromStreamClass discovers API settings from a stream class.
 func (s *streamClassDiscoveryService) DiscoveryFromStreamClass(ctx context.Context, streamClass string, namespace string) (*models.ClientApiSettings, error) {
-	streamClassResourceRef := schema.GroupVersionResource{
-		Group:    "streaming.sneaksanddata.com",
-		Version:  "v1beta1",
-		Resource: "stream-classes",
-	}
 	dynamicClient := s.dynamicInterface.Resource(streamClassResourceRef).Namespace(namespace)
 	streamClassValue, err := dynamicClient.Get(ctx, streamClass, v1.GetOptions{})
-
+	if apierrors.IsNotFound(err) {
+		return nil, &abstractions.StreamClassNotFoundError{StreamClass: streamClass, Namespace: namespace}
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get stream class %s: %w", streamClass, err)
 	}
@@ -91,3 +104,58 @@ func (s *streamClassDiscoveryService) DiscoveryFromStreamClass(ctx context.Conte
 	settings := models.NewClientApiSettings(apiGroup, apiVersion, apiPlural)
 	return settings, nil
 }
+
+// ListStreamClasses lists the names of all stream classes in the namespace.
+func (s *streamClassDiscoveryService) ListStreamClasses(ctx context.Context, namespace string) ([]string, error) {
+	dynamicClient := s.dynamicInterface.Resource(streamClassResourceRef).Namespace(namespace)
+	streamClasses, err := dynamicClient.List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stream classes in namespace %s: %w", namespace, err)
+	}
+
+	names := make([]string, 0, len(streamClasses.Items))
+	for _, streamClass := range streamClasses.Items {
+		names = append(names, streamClass.GetName())
+	}
+	return names, nil
+}
+
+// DiscoveryFromStreamId discovers API settings by looking up the stream in the resources of every stream class.
+func (s *streamClassDiscoveryService) DiscoveryFromStreamId(ctx context.Context, id string, namespace string) (*models.ClientApiSettings, string, error) {
+	streamClasses, err := s.ListStreamClasses(ctx, namespace)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var settings *models.ClientApiSettings
+	matches := make([]string, 0, 1)
+	for _, streamClass := range streamClasses {
+		classSettings, err := s.DiscoveryFromStreamClass(ctx, streamClass, namespace)
+		if err != nil {
+			// A broken stream class must not prevent finding the streams of the other stream classes
+			s.logger.Warn("Skipping stream class", "streamClass", streamClass, "error", err)
+			continue
+		}
+
+		dynamicClient := s.dynamicInterface.Resource(classSettings.ToGroupVersionResource()).Namespace(namespace)
+		_, err = dynamicClient.Get(ctx, id, v1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get stream %s of stream class %s: %w", id, streamClass, err)
+		}
+		s.logger.Debug("Found stream in stream class", "id", id, "streamClass", streamClass)
+		settings = classSettings
+		matches = append(matches, streamClass)
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, "", &abstractions.StreamNotFoundError{Id: id, Namespace: namespace}
+	case 1:
+		return settings, matches[0], nil
+	default:
+		return nil, "", &abstractions.AmbiguousStreamError{Id: id, StreamClasses: matches}
+	}
+}
@@ -6,13 +6,21 @@ import (
 	"fmt"
 	"log/slog"
 	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/diff"
 	"s-vitaliy/kubectl-plugin-arcane/internal/models"
 	"strings"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+	"sigs.k8s.io/yaml"
 )
 
 type streamClassOperationService struct {
@@ -31,7 +39,7 @@ func ProvideStreamClassOperationService(client dynamic.Interface, logger *slog.L
 }
 
 // Suspend implements abstractions.StreamClassOperator.
-func (s *streamClassOperationService) Suspend(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings) error {
+func (s *streamClassOperationService) Suspend(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings, dryRun models.DryRunMode) (*models.PatchResult, error) {
 	annotation := map[string]any{
 		"metadata": map[string]any{
 			"annotations": map[string]string{
@@ -39,11 +47,11 @@ func (s *streamClassOperationService) Suspend(ctx context.Context, id string, na
 			},
 		},
 	}
-	return s.patchObject(ctx, id, namespace, apiSettings, annotation)
+	return s.patchObject(ctx, id, namespace, apiSettings, annotation, dryRun)
 }
 
 // Resume implements abstractions.StreamClassOperator.
-func (s *streamClassOperationService) Resume(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings) error {
+func (s *streamClassOperationService) Resume(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings, dryRun models.DryRunMode) (*models.PatchResult, error) {
 	annotation := map[string]any{
 		"metadata": map[string]any{
 			"annotations": map[string]any{
@@ -51,11 +59,11 @@ func (s *streamClassOperationService) Resume(ctx context.Context, id string, nam
 			},
 		},
 	}
-	return s.patchObject(ctx, id, namespace, apiSettings, annotation)
+	return s.patchObject(ctx, id, namespace, apiSettings, annotation, dryRun)
 }
 
 // Backfill implements abstractions.StreamClassOperator.
-func (s *streamClassOperationService) Backfill(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings) error {
+func (s *streamClassOperationService) Backfill(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings, dryRun models.DryRunMode) (*models.PatchResult, error) {
 	s.logger.Info("Restarting the stream in backfill mode", "id", id)
 	annotation := map[string]any{
 		"metadata": map[string]any{
@@ -64,79 +72,313 @@ func (s *streamClassOperationService) Backfill(ctx context.Context, id string, n
 			},
 		},
 	}
-	return s.patchObject(ctx, id, namespace, apiSettings, annotation)
+	return s.patchObject(ctx, id, namespace, apiSettings, annotation, dryRun)
+}
+
+// Patch implements abstractions.StreamClassOperator.
+func (s *streamClassOperationService) Patch(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings, patch map[string]any, dryRun models.DryRunMode) (*models.PatchResult, error) {
+	s.logger.Info("Patching the stream resource", "id", id)
+	return s.patchObject(ctx, id, namespace, apiSettings, patch, dryRun)
 }
 
 // WaitForStatus implements abstractions.StreamClassOperator.
-func (s *streamClassOperationService) WaitForStatus(ctx context.Context, targetPhase abstractions.StreamPhase, id string, namespace string, apiSettings *models.ClientApiSettings) error {
+// The wait is based on an informer: it lists the stream and then watches it from the listed resourceVersion,
+// re-listing on watch expiry, 410 Gone and lost connections, so it can last as long as the context allows.
+// The initial list makes the current phase of the stream the first one checked.
+func (s *streamClassOperationService) WaitForStatus(ctx context.Context, condition abstractions.WaitCondition, id string, namespace string, apiSettings *models.ClientApiSettings) error {
+	s.logger.Info("Waiting for stream status", "id", id, "targetPhase", condition.Phase)
+
+	_, err := watchtools.UntilWithSync(ctx, s.newListWatch(id, namespace, apiSettings), &unstructured.Unstructured{}, nil, func(event watch.Event) (bool, error) {
+		if event.Type == watch.Deleted {
+			return false, fmt.Errorf("stream %s was deleted", id)
+		}
+
+		stream, ok := event.Object.(*unstructured.Unstructured)
+		if !ok {
+			return false, fmt.Errorf("unexpected type %T received from watch channel", event.Object)
+		}
+		if stream.GetName() != id {
+			return false, nil
+		}
+
+		phase, found, err := unstructured.NestedString(stream.Object, "status", "phase")
+		if err != nil {
+			return false, fmt.Errorf("failed to get phase from stream %s: %w", id, err)
+		}
+
+		s.logger.Info("Stream status update", "id", id, "phase", phase, "resourceVersion", stream.GetResourceVersion())
+		if found && strings.EqualFold(phase, condition.Phase.String()) {
+			s.logger.Info("Stream reached desired status", "id", id, "status", phase)
+			return true, nil
+		}
+		if failure := streamFailure(stream, condition); failure != nil {
+			s.logger.Error("Stream failed while waiting for status", "id", id, "targetPhase", condition.Phase, "error", failure)
+			return false, failure
+		}
+		if !found {
+			s.logger.Debug("Phase not found in stream", "id", id)
+			return false, nil
+		}
+		if isPassedPhase(phase, condition.PassedPhases) && observedPatch(stream, condition.Patch) {
+			s.logger.Info("Stream moved past desired status", "id", id, "status", condition.Phase, "phase", phase)
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("context cancelled while waiting for stream %s status: %w", id, ctx.Err())
+		}
+		return fmt.Errorf("failed to wait for stream %s status: %w", id, err)
+	}
+	return nil
+}
+
+// streamFailure returns the error if the stream has failed after the patch being waited for was applied.
+// The Failed phase is not a failure when it is the phase being waited for or the condition ignores the failures.
+func streamFailure(stream *unstructured.Unstructured, condition abstractions.WaitCondition) *abstractions.StreamFailedError {
+	if condition.Phase == abstractions.StreamPhaseFailed || condition.IgnoreFailure {
+		return nil
+	}
+	if condition.Patch != nil && !observedPatch(stream, condition.Patch) {
+		return nil
+	}
+
+	status := models.StatusFromStreamObject(stream)
+	if !strings.EqualFold(status.Phase, abstractions.StreamPhaseFailed.String()) && len(status.TerminalConditions()) == 0 {
+		return nil
+	}
+	return &abstractions.StreamFailedError{
+		Id:         stream.GetName(),
+		Phase:      status.Phase,
+		Message:    status.Message,
+		Conditions: status.Conditions,
+	}
+}
+
+// isPassedPhase checks whether the phase is one of the phases following a transient phase.
+func isPassedPhase(phase string, passedPhases []abstractions.StreamPhase) bool {
+	for _, passed := range passedPhases {
+		if strings.EqualFold(phase, passed.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+// observedPatch checks whether the operator has reacted to the patch. The arcane/state patches change only
+// the annotations, so they do not bump the generation and any later write makes the stream a newer version.
+// The operator has reacted once it has consumed the state requested by the patch or changed the phase of the stream.
+func observedPatch(stream *unstructured.Unstructured, patch *models.PatchResult) bool {
+	if patch == nil || patch.ResourceVersion == "" || stream.GetResourceVersion() == patch.ResourceVersion {
+		return false
+	}
+	if stream.GetAnnotations()[models.StateAnnotation] != patch.State {
+		return true
+	}
+	phase, _, _ := unstructured.NestedString(stream.Object, "status", "phase")
+	return !strings.EqualFold(phase, patch.Phase)
+}
+
+// newListWatch creates the list and watch functions limited to a single stream.
+func (s *streamClassOperationService) newListWatch(id string, namespace string, apiSettings *models.ClientApiSettings) *cache.ListWatch {
 	dynamicClient := s.client.Resource(apiSettings.ToGroupVersionResource()).Namespace(namespace)
-	watcher, err := dynamicClient.Watch(ctx, v1.ListOptions{
-		FieldSelector: fmt.Sprintf("metadata.name=%s", id),
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", id).String()
+	return &cache.ListWatch{
+		ListWithContextFunc: func(ctx context.Context, options v1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			// Read the latest version instead of a possibly stale copy from the watch cache,
+			// otherwise a version older than the patch being waited for could be taken for a newer one.
+			options.ResourceVersion = ""
+			return dynamicClient.List(ctx, options)
+		},
+		WatchFuncWithContext: func(ctx context.Context, options v1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return dynamicClient.Watch(ctx, options)
+		},
+	}
+}
+
+// GetStatus implements abstractions.StreamClassOperator.
+func (s *streamClassOperationService) GetStatus(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings) (*models.StreamStatus, error) {
+	stream, err := s.GetStream(ctx, id, namespace, apiSettings)
+	if err != nil {
+		return nil, err
+	}
+	return models.StatusFromStreamObject(stream), nil
+}
+
+// Create implements abstractions.StreamClassOperator.
+func (s *streamClassOperationService) Create(ctx context.Context, stream *unstructured.Unstructured, namespace string, apiSettings *models.ClientApiSettings, dryRun models.DryRunMode) (*unstructured.Unstructured, error) {
+	s.logger.Debug("Creating stream object", "id", stream.GetName(), "namespace", namespace, "apiSettings", apiSettings, "dryRun", dryRun)
+	options := v1.CreateOptions{}
+	if dryRun == models.DryRunServer {
+		options.DryRun = []string{v1.DryRunAll}
+	}
+	created, err := s.client.Resource(apiSettings.ToGroupVersionResource()).Namespace(namespace).Create(ctx, stream, options)
+	if err != nil {
+		s.logger.Error("Failed to create stream", "id", stream.GetName(), "error", err)
+		return nil, fmt.Errorf("failed to create stream %s: %w", stream.GetName(), err)
+	}
+	s.logger.Info("Stream created successfully", "id", created.GetName(), "dryRun", dryRun)
+	return created, nil
+}
+
+// Delete implements abstractions.StreamClassOperator.
+func (s *streamClassOperationService) Delete(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings, cascade models.CascadeMode, dryRun models.DryRunMode) error {
+	s.logger.Debug("Deleting stream object", "id", id, "namespace", namespace, "apiSettings", apiSettings, "cascade", cascade, "dryRun", dryRun)
+	if dryRun == models.DryRunClient {
+		s.logger.Info("Stream is not deleted in the client dry run mode", "id", id)
+		return nil
+	}
+	propagation := propagationPolicies[cascade]
+	options := v1.DeleteOptions{PropagationPolicy: &propagation}
+	if dryRun == models.DryRunServer {
+		options.DryRun = []string{v1.DryRunAll}
+	}
+	err := s.client.Resource(apiSettings.ToGroupVersionResource()).Namespace(namespace).Delete(ctx, id, options)
+	if apierrors.IsNotFound(err) {
+		return &abstractions.StreamNotFoundError{Id: id, Namespace: namespace}
+	}
+	if err != nil {
+		s.logger.Error("Failed to delete stream", "id", id, "error", err)
+		return fmt.Errorf("failed to delete stream %s: %w", id, err)
+	}
+	s.logger.Info("Stream deleted successfully", "id", id, "dryRun", dryRun)
+	return nil
+}
+
+var propagationPolicies = map[models.CascadeMode]v1.DeletionPropagation{
+	"":                       v1.DeletePropagationBackground,
+	models.CascadeBackground: v1.DeletePropagationBackground,
+	models.CascadeForeground: v1.DeletePropagationForeground,
+	models.CascadeOrphan:     v1.DeletePropagationOrphan,
+}
+
+// WaitForDeletion implements abstractions.StreamClassOperator.
+func (s *streamClassOperationService) WaitForDeletion(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings) error {
+	s.logger.Info("Waiting for stream deletion", "id", id)
+	_, err := watchtools.UntilWithSync(ctx, s.newListWatch(id, namespace, apiSettings), &unstructured.Unstructured{}, func(store cache.Store) (bool, error) {
+		return len(store.List()) == 0, nil
+	}, func(event watch.Event) (bool, error) {
+		return event.Type == watch.Deleted, nil
 	})
 	if err != nil {
-		return fmt.Errorf("failed to watch stream %s: %w", id, err)
-	}
-	defer watcher.Stop()
-
-	s.logger.Info("Waiting for stream status", "id", id, "targetPhase", targetPhase)
-
-	for {
-		select {
-		case <-ctx.Done():
-			return fmt.Errorf("context cancelled while waiting for stream %s status", id)
-		case event, ok := <-watcher.ResultChan():
-			if !ok {
-				return fmt.Errorf("watch channel closed for stream %s", id)
-			}
-
-			s.logger.Info("Received stream status update", "id", id)
-			stream, ok := event.Object.(*unstructured.Unstructured)
-
-			if !ok {
-				return fmt.Errorf("watch channel closed for stream %s", id)
-			}
-
-			if !ok {
-				return fmt.Errorf("unexpected type %T received from watch channel", event.Object)
-			}
-
-			phase, found, err := unstructured.NestedString(stream.Object, "status", "phase")
-			if err != nil {
-				return fmt.Errorf("failed to get phase from stream %s: %w", id, err)
-			}
-			if !found {
-				return fmt.Errorf("phase not found in stream %s", id)
-			}
-
-			s.logger.Info("Stream status update", "id", id, "phase", phase)
-			if strings.EqualFold(phase, targetPhase.String()) {
-				s.logger.Info("Stream reached desired status", "id", id, "status", phase)
-				return nil
-			}
+		if ctx.Err() != nil {
+			return fmt.Errorf("context cancelled while waiting for stream %s deletion: %w", id, ctx.Err())
 		}
+		return fmt.Errorf("failed to wait for stream %s deletion: %w", id, err)
 	}
+	return nil
 }
 
-func (s *streamClassOperationService) patchObject(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings, annotation map[string]any) error {
-	s.logger.Debug("Patching stream object", "id", id, "namespace", namespace, "apiSettings", apiSettings)
-	if len(annotation) == 0 {
-		return fmt.Errorf("no annotations provided for patching stream %s", id)
+// GetStream implements abstractions.StreamClassOperator.
+func (s *streamClassOperationService) GetStream(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings) (*unstructured.Unstructured, error) {
+	dynamicClient := s.client.Resource(apiSettings.ToGroupVersionResource()).Namespace(namespace)
+	stream, err := dynamicClient.Get(ctx, id, v1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, &abstractions.StreamNotFoundError{Id: id, Namespace: namespace}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stream %s: %w", id, err)
+	}
+	return stream, nil
+}
+
+// List implements abstractions.StreamClassOperator.
+func (s *streamClassOperationService) List(ctx context.Context, namespace string, labelSelector string, apiSettings *models.ClientApiSettings) ([]*models.StreamSummary, error) {
+	dynamicClient := s.client.Resource(apiSettings.ToGroupVersionResource()).Namespace(namespace)
+	streams, err := dynamicClient.List(ctx, v1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list streams %s: %w", apiSettings, err)
+	}
+
+	summaries := make([]*models.StreamSummary, 0, len(streams.Items))
+	for i := range streams.Items {
+		summaries = append(summaries, models.FromStreamObject(&streams.Items[i]))
+	}
+	return summaries, nil
+}
+
+func (s *streamClassOperationService) patchObject(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings, patch map[string]any, dryRun models.DryRunMode) (*models.PatchResult, error) {
+	s.logger.Debug("Patching stream object", "id", id, "namespace", namespace, "apiSettings", apiSettings, "dryRun", dryRun)
+	if len(patch) == 0 {
+		return nil, fmt.Errorf("no changes provided for patching stream %s", id)
 	}
 
 	dynamicClient := s.client.Resource(apiSettings.ToGroupVersionResource()).Namespace(namespace)
-	patchBytes, err := json.Marshal(annotation)
+	patchBytes, err := json.Marshal(patch)
 	if err != nil {
-		return fmt.Errorf("failed to marshal annotation: %w", err)
+		return nil, fmt.Errorf("failed to marshal patch: %w", err)
 	}
-	_, err = dynamicClient.Patch(ctx,
+	result := &models.PatchResult{Resource: apiSettings.ResourceName(), Name: id, Patch: string(patchBytes)}
+
+	switch dryRun {
+	case models.DryRunClient:
+		result.DryRun = dryRun
+		s.logger.Info("Stream patch is not sent in the client dry run mode", "id", id)
+		return result, nil
+	case models.DryRunServer:
+		result.DryRun = dryRun
+		result.Diff, err = s.previewPatch(ctx, dynamicClient, id, patchBytes)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	patched, err := dynamicClient.Patch(ctx,
 		id,
 		types.MergePatchType,
 		patchBytes,
 		v1.PatchOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, &abstractions.StreamNotFoundError{Id: id, Namespace: namespace}
+	}
 	if err != nil {
 		s.logger.Error("Failed to patch stream", "id", id, "error", err)
-		return fmt.Errorf("failed to patch stream %s: %w", id, err)
+		return nil, fmt.Errorf("failed to patch stream %s: %w", id, err)
 	}
-	s.logger.Info("Stream patched successfully", "id", id)
-	return nil
+	result.ResourceVersion = patched.GetResourceVersion()
+	result.State = patched.GetAnnotations()[models.StateAnnotation]
+	result.Phase, _, _ = unstructured.NestedString(patched.Object, "status", "phase")
+	s.logger.Info("Stream patched successfully", "id", id, "resourceVersion", result.ResourceVersion)
+	return result, nil
+}
+
+// previewPatch sends the patch in the server dry run mode and returns the diff between the live and the patched object.
+func (s *streamClassOperationService) previewPatch(ctx context.Context, dynamicClient dynamic.ResourceInterface, id string, patchBytes []byte) (string, error) {
+	live, err := dynamicClient.Get(ctx, id, v1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get stream %s: %w", id, err)
+	}
+
+	patched, err := dynamicClient.Patch(ctx,
+		id,
+		types.MergePatchType,
+		patchBytes,
+		v1.PatchOptions{DryRun: []string{v1.DryRunAll}})
+	if err != nil {
+		return "", fmt.Errorf("failed to patch stream %s in the dry run mode: %w", id, err)
+	}
+
+	liveYaml, err := toYaml(live)
+	if err != nil {
+		return "", err
+	}
+	patchedYaml, err := toYaml(patched)
+	if err != nil {
+		return "", err
+	}
+	return diff.Unified("live/"+id, "patched/"+id, liveYaml, patchedYaml), nil
+}
+
+func toYaml(object *unstructured.Unstructured) (string, error) {
+	object = object.DeepCopy()
+	object.SetManagedFields(nil)
+	data, err := yaml.Marshal(object.Object)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal stream %s: %w", object.GetName(), err)
+	}
+	return string(data), nil
 }
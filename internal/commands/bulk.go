@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"s-vitaliy/kubectl-plugin-arcane/internal/output"
+)
+
+// StreamSelector selects the streams for the commands supporting bulk operations.
+// The bulk mode is used when the stream ID is not provided.
+type StreamSelector struct {
+	Selector    string `short:"l" help:"Selector (label query) to filter streams on, e.g. -l key1=value1,key2=value2."`
+	All         bool   `help:"Select all streams in the namespace."`
+	Parallelism int    `help:"The maximum number of streams processed at the same time in the bulk mode." default:"4"`
+}
+
+// validate checks that the command either targets a single stream or selects the streams for the bulk mode.
+func (s *StreamSelector) validate(id string, streamClass string) error {
+	if id != "" && (s.All || s.Selector != "") {
+		return fmt.Errorf("the stream ID cannot be combined with --all or --selector")
+	}
+	if s.All && s.Selector != "" {
+		return fmt.Errorf("--all cannot be combined with --selector")
+	}
+	if id == "" && !s.All && s.Selector == "" && streamClass == "" {
+		return fmt.Errorf("the stream ID, --selector, --class or --all is required")
+	}
+	return nil
+}
+
+// runBulk applies the operation to all streams matching the selector and prints the per-stream results.
+// It returns an error if the operation failed for any stream.
+func (s *StreamSelector) runBulk(h abstractions.StreamCommandHandler, printer output.Printer, namespace string, streamClass string, operation app.StreamOperation) error {
+	filter := models.StreamFilter{StreamClass: streamClass, LabelSelector: s.Selector}
+	streams, err := h.List(context.Background(), namespace, filter)
+	if err != nil {
+		return err
+	}
+	if len(streams.Items) == 0 {
+		return fmt.Errorf("no streams found matching the selection")
+	}
+
+	result := app.RunBulkOperation(streams.Items, s.Parallelism, operation)
+	if err := printer.Print(result); err != nil {
+		return err
+	}
+	return result.Err()
+}
@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/bundle"
+	"s-vitaliy/kubectl-plugin-arcane/internal/client/api/common"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"s-vitaliy/kubectl-plugin-arcane/internal/output"
+
+	"go.uber.org/dig"
+)
+
+// Represents the command to export streams as a bundle.
+type ExportCmd struct {
+	Ids      []string `arg:"" optional:"" help:"The IDs of the streams to export."`
+	Class    string   `help:"Export the streams of the stream class. With the stream IDs, the class of the streams, discovered if not provided."`
+	Selector string   `short:"l" help:"Selector (label query) to filter streams on, e.g. -l key1=value1,key2=value2."`
+	All      bool     `help:"Export all streams in the namespace."`
+	ToFile   string   `help:"Write the bundle to the file as multi-document yaml instead of the standard output."`
+	ToDir    string   `help:"Write the bundle to the directory, one yaml file per object."`
+}
+
+func (r *ExportCmd) Validate() error {
+	if len(r.Ids) > 0 && (r.All || r.Selector != "") {
+		return fmt.Errorf("the stream IDs cannot be combined with --all or --selector")
+	}
+	if r.All && r.Selector != "" {
+		return fmt.Errorf("--all cannot be combined with --selector")
+	}
+	if len(r.Ids) == 0 && !r.All && r.Selector == "" && r.Class == "" {
+		return fmt.Errorf("the stream IDs, --selector, --class or --all is required")
+	}
+	if r.ToFile != "" && r.ToDir != "" {
+		return fmt.Errorf("--to-file cannot be combined with --to-dir")
+	}
+	return nil
+}
+
+func (r *ExportCmd) Run(container *dig.Container) error {
+	err := container.Invoke(func(h abstractions.StreamCommandHandler, reader common.ConfigReader, printer output.Printer) error {
+		if h != nil {
+			namespace, err := reader.ReadNamespace()
+			if err != nil {
+				return err
+			}
+			filter := models.StreamFilter{StreamClass: r.Class, LabelSelector: r.Selector}
+			exported, err := h.Export(context.Background(), namespace, r.Ids, filter)
+			if err != nil {
+				return err
+			}
+			if r.ToFile == "" && r.ToDir == "" {
+				return printer.Print(exported)
+			}
+
+			if r.ToDir != "" {
+				if _, err := bundle.WriteDir(r.ToDir, exported); err != nil {
+					return err
+				}
+			} else if err := writeBundleFile(r.ToFile, exported); err != nil {
+				return err
+			}
+			result := &models.BundleResult{}
+			for _, object := range exported.Objects {
+				result.Items = append(result.Items, &models.BundleItem{Kind: object.GetKind(), Name: object.GetName(), Operation: "exported"})
+			}
+			return printer.Print(result)
+		}
+		return fmt.Errorf("no handler provided for exporting streams")
+	})
+	return err
+}
+
+// writeBundleFile writes the bundle to the file as multi-document yaml.
+func writeBundleFile(path string, exported *models.StreamBundle) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle file %s: %w", path, err)
+	}
+	if err := bundle.Write(file, exported); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write bundle file %s: %w", path, err)
+	}
+	return file.Close()
+}
+
+// Represents the command to import a bundle of streams.
+type ImportCmd struct {
+	FromFile  string `short:"f" required:"" help:"The bundle file or directory, - reads the bundle from the standard input."`
+	KeepState bool   `help:"Apply the stream states recorded in the bundle. Otherwise the new streams are created suspended and the state of the existing streams is not changed."`
+	DryRun    string `help:"Must be \"none\", \"client\", or \"server\". If client, only print the changes that would be made. If server, submit the changes in the server dry run mode." enum:"none,client,server" default:"none"`
+}
+
+func (r *ImportCmd) Run(container *dig.Container) error {
+	imported, err := bundle.Read(r.FromFile)
+	if err != nil {
+		return err
+	}
+	err = container.Invoke(func(h abstractions.StreamCommandHandler, reader common.ConfigReader, printer output.Printer) error {
+		if h != nil {
+			namespace, err := reader.ReadNamespace()
+			if err != nil {
+				return err
+			}
+			options := models.ImportOptions{DryRun: models.DryRunMode(r.DryRun), KeepState: r.KeepState}
+			result, err := h.Import(context.Background(), namespace, imported, options)
+			if err != nil {
+				return err
+			}
+			if err := printer.Print(result); err != nil {
+				return err
+			}
+			return result.Err()
+		}
+		return fmt.Errorf("no handler provided for importing streams")
+	})
+	return err
+}
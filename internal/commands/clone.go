@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/client/api/common"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"s-vitaliy/kubectl-plugin-arcane/internal/output"
+	"s-vitaliy/kubectl-plugin-arcane/internal/prompt"
+
+	"go.uber.org/dig"
+)
+
+// Represents the command to copy a stream under a new name.
+type CloneCmd struct {
+	Source      string   `arg:"" help:"The ID of the stream to copy."`
+	Name        string   `arg:"" help:"The name of the new stream."`
+	Class       string   `help:"The class of the stream, discovered from the stream ID if not provided."`
+	Set         []string `placeholder:"PATH=VALUE" help:"Override the spec field of the copy, e.g. --set sourceSettings.table=orders. The paths are relative to the stream spec."`
+	ToNamespace string   `help:"The namespace of the new stream, the namespace of the source stream if not provided."`
+	ToContext   string   `help:"The kubeconfig context of the cluster to create the new stream in, the current cluster if not provided."`
+	DryRun      string   `help:"Must be \"none\", \"client\", or \"server\". If client, only print the stream that would be created. If server, submit the stream in the server dry run mode and print the result." enum:"none,client,server" default:"none"`
+}
+
+func (r *CloneCmd) Validate() error {
+	_, err := parseFieldValues(r.Set)
+	return err
+}
+
+func (r *CloneCmd) Run(container *dig.Container) error {
+	values, err := parseFieldValues(r.Set)
+	if err != nil {
+		return err
+	}
+	err = container.Invoke(func(h abstractions.StreamCommandHandler, reader common.ConfigReader, printer output.Printer, newHandler abstractions.StreamCommandHandlerFactory, prompter *prompt.Prompter) error {
+		if h != nil {
+			namespace, err := reader.ReadNamespace()
+			if err != nil {
+				return err
+			}
+			var target abstractions.StreamCreateHandler
+			if r.ToContext != "" {
+				target, err = newHandler(r.ToContext)
+				if err != nil {
+					return fmt.Errorf("failed to connect to context %s: %w", r.ToContext, err)
+				}
+			}
+			options := models.CloneOptions{Name: r.Name, Namespace: r.ToNamespace, Values: values, DryRun: models.DryRunMode(r.DryRun)}
+			result, err := withStreamClass(prompter, r.Class, func(streamClass string) (*models.OperationResult, error) {
+				return h.Clone(context.Background(), r.Source, namespace, streamClass, options, target)
+			})
+			if err != nil {
+				return err
+			}
+			return printer.Print(result)
+		}
+		return fmt.Errorf("no handler provided for cloning stream")
+	})
+	return err
+}
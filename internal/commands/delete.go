@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/client/api/common"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"s-vitaliy/kubectl-plugin-arcane/internal/output"
+	"s-vitaliy/kubectl-plugin-arcane/internal/prompt"
+	"time"
+
+	"go.uber.org/dig"
+)
+
+// Represents the command to delete a stream.
+type DeleteCmd struct {
+	Id       string `arg:"" help:"The ID of the stream to delete."`
+	Class    string `help:"The class of the stream, discovered from the stream ID if not provided."`
+	Cascade  string `help:"Must be \"background\", \"foreground\", or \"orphan\". Defines whether the resources owned by the stream are deleted in the background, before the stream, or kept." enum:"background,foreground,orphan" default:"background"`
+	Wait     bool   `help:"Wait for the stream resource to be removed."`
+	Force    bool   `help:"Delete the stream right away, without suspending it and waiting for its job to be removed."`
+	Yes      bool   `short:"y" help:"Delete the stream without asking for confirmation."`
+	Deadline string `help:"The deadline for the delete operation." default:"5m"`
+	DryRun   string `help:"Must be \"none\", \"client\", or \"server\". If client, only print the changes that would be made. If server, submit the changes in the server dry run mode." enum:"none,client,server" default:"none"`
+}
+
+func (r *DeleteCmd) Run(container *dig.Container) error {
+	err := container.Invoke(func(h abstractions.StreamCommandHandler, reader common.ConfigReader, printer output.Printer, progress *output.ProgressView, prompter *prompt.Prompter) error {
+		if h != nil {
+			namespace, err := reader.ReadNamespace()
+			if err != nil {
+				return err
+			}
+			duration, err := time.ParseDuration(r.Deadline)
+			if err != nil {
+				return fmt.Errorf("failed to parse deadline %s: %w", r.Deadline, err)
+			}
+			options := models.DeleteOptions{
+				OperationOptions: models.OperationOptions{Wait: r.Wait, DryRun: models.DryRunMode(r.DryRun), Force: r.Force},
+				Cascade:          models.CascadeMode(r.Cascade),
+			}
+			if !options.DryRun.IsDryRun() {
+				if err := r.confirm(prompter, namespace); err != nil {
+					return err
+				}
+				options.Progress = progress.Update
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), duration)
+			defer cancel()
+			result, err := withStreamClass(prompter, r.Class, func(streamClass string) (*models.OperationResult, error) {
+				return h.Delete(ctx, r.Id, namespace, streamClass, options)
+			})
+			progress.Clear()
+			if err != nil {
+				return err
+			}
+			return printer.Print(result)
+		}
+		return fmt.Errorf("no handler provided for deleting stream")
+	})
+	return err
+}
+
+// confirm asks the user to confirm the deletion unless it is confirmed with --yes.
+func (r *DeleteCmd) confirm(prompter *prompt.Prompter, namespace string) error {
+	if r.Yes {
+		return nil
+	}
+	if !prompter.IsInteractive() {
+		return fmt.Errorf("deleting stream %s must be confirmed with --yes when the input is not a terminal", r.Id)
+	}
+	confirmed, err := prompter.Confirm(fmt.Sprintf("Delete stream %s in namespace %s?", r.Id, namespace))
+	if err != nil {
+		return fmt.Errorf("failed to read the confirmation: %w", err)
+	}
+	if !confirmed {
+		return fmt.Errorf("deletion of stream %s is cancelled", r.Id)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/client/api/common"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"s-vitaliy/kubectl-plugin-arcane/internal/output"
+
+	"go.uber.org/dig"
+	"golang.org/x/term"
+)
+
+// Represents the command to compare a stream manifest with the live stream.
+type DiffCmd struct {
+	FromFile     string `short:"f" required:"" help:"The file with the stream manifest, - reads the manifest from the standard input."`
+	Class        string `help:"The class of the stream, discovered from the kind of the manifest if not provided."`
+	IncludeState bool   `help:"Compare the arcane/state annotation, which is ignored by default."`
+	Color        string `help:"Must be \"auto\", \"always\", or \"never\". If auto, the diff is colored when the output is a terminal." enum:"auto,always,never" default:"auto"`
+}
+
+func (r *DiffCmd) Run(container *dig.Container) error {
+	manifest, err := readManifest(r.FromFile)
+	if err != nil {
+		return err
+	}
+	err = container.Invoke(func(h abstractions.StreamCommandHandler, reader common.ConfigReader, printer output.Printer) error {
+		if h != nil {
+			namespace, err := reader.ReadNamespace()
+			if err != nil {
+				return err
+			}
+			options := models.DiffOptions{IncludeState: r.IncludeState}
+			result, err := h.Diff(context.Background(), namespace, r.Class, manifest, options)
+			if err != nil {
+				return err
+			}
+			result.Color = r.colored()
+			if err := printer.Print(result); err != nil {
+				return err
+			}
+			// Like kubectl diff, the command exits with 1 if there are differences, so it can detect the drift in CI
+			if result.IsDifferent() {
+				return &abstractions.StreamDiffersError{Name: result.Name, Namespace: result.Namespace}
+			}
+			return nil
+		}
+		return fmt.Errorf("no handler provided for comparing stream")
+	})
+	return err
+}
+
+// colored decides whether the diff is colored, the auto mode follows the NO_COLOR convention.
+func (r *DiffCmd) colored() bool {
+	switch r.Color {
+	case "always":
+		return true
+	case "never":
+		return false
+	default:
+		_, noColor := os.LookupEnv("NO_COLOR")
+		return !noColor && term.IsTerminal(int(os.Stdout.Fd()))
+	}
+}
@@ -0,0 +1,152 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/client/api/common"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"s-vitaliy/kubectl-plugin-arcane/internal/output"
+	"s-vitaliy/kubectl-plugin-arcane/internal/prompt"
+	"s-vitaliy/kubectl-plugin-arcane/internal/schema"
+	"strings"
+
+	"go.uber.org/dig"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Represents the command to create a stream.
+type CreateCmd struct {
+	Class    string `arg:"" help:"The stream class of the new stream."`
+	Name     string `arg:"" help:"The name of the new stream."`
+	FromFile string `short:"f" help:"The file with the stream manifest, - reads the manifest from the standard input. Without the file, the required values are asked interactively."`
+	Template bool   `help:"Print the skeleton manifest built from the stream schema instead of creating the stream."`
+	Running  bool   `help:"Create the stream running instead of suspended."`
+	DryRun   string `help:"Must be \"none\", \"client\", or \"server\". If client, only print the stream that would be created. If server, submit the stream in the server dry run mode and print the result." enum:"none,client,server" default:"none"`
+}
+
+func (r *CreateCmd) Validate() error {
+	if r.Template && r.FromFile != "" {
+		return fmt.Errorf("--template cannot be used with --from-file")
+	}
+	return nil
+}
+
+func (r *CreateCmd) Run(container *dig.Container) error {
+	err := container.Invoke(func(h abstractions.StreamCommandHandler, reader common.ConfigReader, printer output.Printer, prompter *prompt.Prompter) error {
+		if h != nil {
+			namespace, err := reader.ReadNamespace()
+			if err != nil {
+				return err
+			}
+			if r.Template {
+				template, err := h.Scaffold(context.Background(), namespace, r.Class, r.Name)
+				if err != nil {
+					return err
+				}
+				return printer.Print(template.Manifest)
+			}
+
+			var manifest map[string]any
+			if r.FromFile != "" {
+				manifest, err = readManifest(r.FromFile)
+			} else {
+				manifest, err = r.askManifest(h, prompter, namespace)
+			}
+			if err != nil {
+				return err
+			}
+			options := models.CreateOptions{DryRun: models.DryRunMode(r.DryRun), Running: r.Running}
+			result, err := h.Create(context.Background(), namespace, r.Class, r.Name, manifest, options)
+			if err != nil {
+				return err
+			}
+			return printer.Print(result)
+		}
+		return fmt.Errorf("no handler provided for creating stream")
+	})
+	return err
+}
+
+// askManifest scaffolds the stream and asks the user for the required values, an empty answer keeps the skeleton value.
+func (r *CreateCmd) askManifest(h abstractions.StreamCommandHandler, prompter *prompt.Prompter, namespace string) (map[string]any, error) {
+	if !prompter.IsInteractive() {
+		return nil, fmt.Errorf("the stream manifest must be provided with --from-file when the input is not a terminal")
+	}
+	template, err := h.Scaffold(context.Background(), namespace, r.Class, r.Name)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range template.Fields {
+		value, err := askField(prompter, field)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			continue
+		}
+		if err := unstructured.SetNestedField(template.Manifest, value, strings.Split(field.Path, ".")...); err != nil {
+			return nil, fmt.Errorf("failed to set %s: %w", field.Path, err)
+		}
+	}
+	return template.Manifest, nil
+}
+
+// askField asks for the value of the field until the answer matches the field type. It returns nil for an empty answer.
+func askField(prompter *prompt.Prompter, field models.SchemaField) (any, error) {
+	question := fmt.Sprintf("%s (%s)", field.Path, valueOrType(field.Type))
+	if len(field.Enum) > 0 {
+		question = fmt.Sprintf("%s, one of %v", question, field.Enum)
+	}
+	if field.Description != "" {
+		question = fmt.Sprintf("# %s\n%s", field.Description, question)
+	}
+	prefix := ""
+	for {
+		answer, err := prompter.Ask(prefix + question + ": ")
+		if err != nil || answer == "" {
+			return nil, err
+		}
+		value, err := schema.ParseValue(map[string]any{"type": field.Type}, answer)
+		if err == nil {
+			return value, nil
+		}
+		prefix = fmt.Sprintf("Invalid value: %v\n", err)
+	}
+}
+
+func valueOrType(fieldType string) string {
+	if fieldType == "" {
+		return "any"
+	}
+	return fieldType
+}
+
+// readManifest reads the yaml or json manifest from the file, - reads the standard input.
+func readManifest(path string) (map[string]any, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", path, err)
+	}
+	value, err := schema.ParseYaml(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", path, err)
+	}
+	manifest, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("manifest %s is not an object", path)
+	}
+	return manifest, nil
+}
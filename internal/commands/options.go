@@ -0,0 +1,14 @@
+package commands
+
+import "s-vitaliy/kubectl-plugin-arcane/internal/models"
+
+// OperationFlags adds the flags shared by the commands changing the stream state.
+type OperationFlags struct {
+	DryRun string `help:"Must be \"none\", \"client\", or \"server\". If client, only print the patch that would be sent. If server, submit the patch in the server dry run mode and print the resulting changes." enum:"none,client,server" default:"none"`
+	Force  bool   `help:"Apply the operation even if the current stream state does not allow it or nothing would change."`
+}
+
+// operationOptions builds the options of the operation changing the stream state.
+func (f *OperationFlags) operationOptions(wait bool) models.OperationOptions {
+	return models.OperationOptions{Wait: wait, DryRun: models.DryRunMode(f.DryRun), Force: f.Force}
+}
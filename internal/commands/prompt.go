@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/prompt"
+	"strconv"
+	"strings"
+)
+
+// withStreamClass runs the operation for the stream class. If the stream ID exists in several stream classes
+// and a user can answer, it asks the user to choose the stream class and runs the operation again.
+func withStreamClass[T any](prompter *prompt.Prompter, streamClass string, run func(streamClass string) (T, error)) (T, error) {
+	result, err := run(streamClass)
+	var ambiguous *abstractions.AmbiguousStreamError
+	if err == nil || !errors.As(err, &ambiguous) || !prompter.IsInteractive() {
+		return result, err
+	}
+
+	chosen, promptErr := chooseStreamClass(prompter, ambiguous)
+	if promptErr != nil {
+		return result, fmt.Errorf("%w: %w", err, promptErr)
+	}
+	return run(chosen)
+}
+
+// chooseStreamClass asks the user to choose one of the stream classes owning the stream.
+func chooseStreamClass(prompter *prompt.Prompter, ambiguous *abstractions.AmbiguousStreamError) (string, error) {
+	var question strings.Builder
+	fmt.Fprintf(&question, "Stream %s exists in several stream classes:\n", ambiguous.Id)
+	for i, streamClass := range ambiguous.StreamClasses {
+		fmt.Fprintf(&question, "  %d) %s\n", i+1, streamClass)
+	}
+	fmt.Fprintf(&question, "Choose the stream class [1-%d]: ", len(ambiguous.StreamClasses))
+
+	answer, err := prompter.Ask(question.String())
+	if err != nil {
+		return "", fmt.Errorf("failed to read the stream class choice: %w", err)
+	}
+	choice, err := strconv.Atoi(answer)
+	if err != nil || choice < 1 || choice > len(ambiguous.StreamClasses) {
+		return "", fmt.Errorf("invalid stream class choice %q", answer)
+	}
+	return ambiguous.StreamClasses[choice-1], nil
+}
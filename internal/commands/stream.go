@@ -3,21 +3,126 @@ package commands
 import (
 	"context"
 	"fmt"
+	"os"
+	"os/signal"
 	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/client/api/common"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"s-vitaliy/kubectl-plugin-arcane/internal/output"
+	"s-vitaliy/kubectl-plugin-arcane/internal/prompt"
 	"time"
 
 	"go.uber.org/dig"
 )
 
+// Represents the command to list streams.
+type ListCmd struct {
+	Selector string `short:"l" help:"Selector (label query) to filter streams on, e.g. -l key1=value1,key2=value2."`
+	Class    string `help:"List only the streams of the given stream class."`
+}
+
+func (r *ListCmd) Run(container *dig.Container) error {
+	err := container.Invoke(func(h abstractions.StreamCommandHandler, reader common.ConfigReader, printer output.Printer) error {
+		if h != nil {
+			namespace, err := reader.ReadNamespace()
+			if err != nil {
+				return err
+			}
+			filter := models.StreamFilter{StreamClass: r.Class, LabelSelector: r.Selector}
+			streams, err := h.List(context.Background(), namespace, filter)
+			if err != nil {
+				return err
+			}
+			return printer.Print(streams)
+		}
+		return fmt.Errorf("no handler provided for listing streams")
+	})
+	return err
+}
+
+// Represents the command to show the stream status.
+type StatusCmd struct {
+	Id    string `arg:"" help:"The ID of the stream."`
+	Class string `help:"The class of the stream, discovered from the stream ID if not provided."`
+}
+
+func (r *StatusCmd) Run(container *dig.Container) error {
+	err := container.Invoke(func(h abstractions.StreamCommandHandler, reader common.ConfigReader, printer output.Printer, prompter *prompt.Prompter) error {
+		if h != nil {
+			namespace, err := reader.ReadNamespace()
+			if err != nil {
+				return err
+			}
+			status, err := withStreamClass(prompter, r.Class, func(streamClass string) (*models.StreamStatus, error) {
+				return h.Status(context.Background(), r.Id, namespace, streamClass)
+			})
+			if err != nil {
+				return err
+			}
+			return printer.Print(status)
+		}
+		return fmt.Errorf("no handler provided for reading stream status")
+	})
+	return err
+}
+
+// Represents the command to describe the stream spec and its references.
+type DescribeCmd struct {
+	Id    string `arg:"" help:"The ID of the stream."`
+	Class string `help:"The class of the stream, discovered from the stream ID if not provided."`
+}
+
+func (r *DescribeCmd) Run(container *dig.Container) error {
+	err := container.Invoke(func(h abstractions.StreamCommandHandler, reader common.ConfigReader, printer output.Printer, prompter *prompt.Prompter) error {
+		if h != nil {
+			namespace, err := reader.ReadNamespace()
+			if err != nil {
+				return err
+			}
+			description, err := withStreamClass(prompter, r.Class, func(streamClass string) (*models.StreamDescription, error) {
+				return h.Describe(context.Background(), r.Id, namespace, streamClass)
+			})
+			if err != nil {
+				return err
+			}
+			return printer.Print(description)
+		}
+		return fmt.Errorf("no handler provided for describing stream")
+	})
+	return err
+}
+
 // Represents the command to suspend a stream.
 type SuspendCmd struct {
-	Id string `arg:"" help:"The ID of the stream to suspend."`
+	Id    string `arg:"" optional:"" help:"The ID of the stream to suspend."`
+	Class string `help:"The class of the stream to suspend. Without the stream ID, suspends all streams of the class."`
+	StreamSelector
+	OperationFlags
+}
+
+func (r *SuspendCmd) Validate() error {
+	return r.validate(r.Id, r.Class)
 }
 
 func (r *SuspendCmd) Run(container *dig.Container) error {
-	err := container.Invoke(func(h abstractions.StreamCommandHandler) error {
+	err := container.Invoke(func(h abstractions.StreamCommandHandler, reader common.ConfigReader, printer output.Printer, prompter *prompt.Prompter) error {
 		if h != nil {
-			return h.Suspend(context.Background(), r.Id)
+			namespace, err := reader.ReadNamespace()
+			if err != nil {
+				return err
+			}
+			if r.Id == "" {
+				return r.runBulk(h, printer, namespace, r.Class, func(stream *models.StreamSummary) (*models.OperationResult, error) {
+					return h.Suspend(context.Background(), stream.Name, namespace, stream.StreamClass, r.operationOptions(false))
+				})
+			}
+			result, err := withStreamClass(prompter, r.Class, func(streamClass string) (*models.OperationResult, error) {
+				return h.Suspend(context.Background(), r.Id, namespace, streamClass, r.operationOptions(false))
+			})
+			if err != nil {
+				return err
+			}
+			return printer.Print(result)
 		}
 		return fmt.Errorf("no handler provided for suspending stream")
 	})
@@ -26,38 +131,113 @@ func (r *SuspendCmd) Run(container *dig.Container) error {
 
 // Represents the command to resume a stream.
 type ResumeCmd struct {
-	Id    string `arg:"" help:"The ID of the stream to resume."`
-	Class string `arg:"" help:"The class of the stream to resume."`
+	Id       string `arg:"" optional:"" help:"The ID of the stream to resume."`
+	ClassArg string `arg:"" optional:"" name:"stream-class" help:"Deprecated, use --class instead."`
+	Class    string `help:"The class of the stream to resume, discovered from the stream ID if not provided. Without the stream ID, resumes all streams of the class."`
+	StreamSelector
+	OperationFlags
+}
+
+func (r *ResumeCmd) Validate() error {
+	if err := deprecatedClassArg(&r.Class, r.ClassArg); err != nil {
+		return err
+	}
+	return r.validate(r.Id, r.Class)
 }
 
 func (r *ResumeCmd) Run(container *dig.Container) error {
-	err := container.Invoke(func(h abstractions.StreamCommandHandler) error {
+	err := container.Invoke(func(h abstractions.StreamCommandHandler, reader common.ConfigReader, printer output.Printer, prompter *prompt.Prompter) error {
 		if h != nil {
-			return h.Resume(context.Background(), r.Id, r.Class)
+			namespace, err := reader.ReadNamespace()
+			if err != nil {
+				return err
+			}
+			if r.Id == "" {
+				return r.runBulk(h, printer, namespace, r.Class, func(stream *models.StreamSummary) (*models.OperationResult, error) {
+					return h.Resume(context.Background(), stream.Name, namespace, stream.StreamClass, r.operationOptions(false))
+				})
+			}
+			result, err := withStreamClass(prompter, r.Class, func(streamClass string) (*models.OperationResult, error) {
+				return h.Resume(context.Background(), r.Id, namespace, streamClass, r.operationOptions(false))
+			})
+			if err != nil {
+				return err
+			}
+			return printer.Print(result)
 		}
 		return fmt.Errorf("no handler provided for resuming stream")
 	})
 	return err
 }
 
+// deprecatedClassArg sets the stream class from the deprecated positional argument kept for the
+// "<id> <class>" invocations, the argument cannot be combined with the --class flag.
+func deprecatedClassArg(class *string, classArg string) error {
+	if classArg == "" {
+		return nil
+	}
+	if *class != "" && *class != classArg {
+		return fmt.Errorf("the stream class argument %s conflicts with --class %s", classArg, *class)
+	}
+	*class = classArg
+	return nil
+}
+
 // Represents the command to backfill a stream.
 type BackfillCmd struct {
-	Id       string `arg:"" help:"The ID of the stream to backfill."`
-	Wait     bool   `help:"Wait for the stream to run a backfill."`
-	Class    string `arg:"" help:"The class of the stream to backfill." default:""`
-	Deadline string `arg:"" help:"The deadline for the backfill operation." default:"60m"`
+	Id          string `arg:"" optional:"" help:"The ID of the stream to backfill."`
+	ClassArg    string `arg:"" optional:"" name:"stream-class" help:"Deprecated, use --class instead."`
+	DeadlineArg string `arg:"" optional:"" name:"backfill-deadline" help:"Deprecated, use --deadline instead."`
+	Wait        bool   `help:"Wait for the stream to run a backfill."`
+	Class       string `help:"The class of the stream to backfill, discovered from the stream ID if not provided. Without the stream ID, backfills all streams of the class."`
+	Deadline    string `help:"The deadline for the backfill operation." default:"60m"`
+	StreamSelector
+	OperationFlags
+}
+
+func (r *BackfillCmd) Validate() error {
+	if err := deprecatedClassArg(&r.Class, r.ClassArg); err != nil {
+		return err
+	}
+	// The flag has a default value, so the positional deadline takes precedence when both are given
+	if r.DeadlineArg != "" {
+		r.Deadline = r.DeadlineArg
+	}
+	return r.validate(r.Id, r.Class)
 }
 
 func (r *BackfillCmd) Run(container *dig.Container) error {
-	err := container.Invoke(func(h abstractions.StreamCommandHandler) error {
+	err := container.Invoke(func(h abstractions.StreamCommandHandler, reader common.ConfigReader, printer output.Printer, progress *output.ProgressView, prompter *prompt.Prompter) error {
 		if h != nil {
+			namespace, err := reader.ReadNamespace()
+			if err != nil {
+				return err
+			}
 			duration, err := time.ParseDuration(r.Deadline)
 			if err != nil {
 				return fmt.Errorf("failed to parse deadline %s: %w", r.Deadline, err)
 			}
+			if r.Id == "" {
+				return r.runBulk(h, printer, namespace, r.Class, func(stream *models.StreamSummary) (*models.OperationResult, error) {
+					ctx, cancel := context.WithTimeout(context.Background(), duration)
+					defer cancel()
+					return h.Backfill(ctx, stream.Name, namespace, stream.StreamClass, r.operationOptions(r.Wait))
+				})
+			}
 			ctx, cancel := context.WithTimeout(context.Background(), duration)
 			defer cancel()
-			return h.Backfill(ctx, r.Id, r.Class, r.Wait)
+			options := r.operationOptions(r.Wait)
+			if r.Wait && !options.DryRun.IsDryRun() {
+				options.Progress = progress.Update
+			}
+			result, err := withStreamClass(prompter, r.Class, func(streamClass string) (*models.OperationResult, error) {
+				return h.Backfill(ctx, r.Id, namespace, streamClass, options)
+			})
+			progress.Clear()
+			if err != nil {
+				return err
+			}
+			return printer.Print(result)
 		}
 		return fmt.Errorf("no handler provided for resuming stream")
 	})
@@ -66,31 +246,126 @@ func (r *BackfillCmd) Run(container *dig.Container) error {
 
 // Represents the command to restart a stream.
 type RestartCmd struct {
-	Id       string `arg:"" help:"The ID of the stream to backfill."`
+	Id       string `arg:"" optional:"" help:"The ID of the stream to backfill."`
 	Wait     bool   `help:"Wait for the stream to restart."`
+	Class    string `help:"The class of the stream to restart. Without the stream ID, restarts all streams of the class."`
 	Deadline string `help:"The deadline for the restart operation." default:"1m"`
+	StreamSelector
+	OperationFlags
+}
+
+func (r *RestartCmd) Validate() error {
+	return r.validate(r.Id, r.Class)
 }
 
 func (r *RestartCmd) Run(container *dig.Container) error {
-	err := container.Invoke(func(h abstractions.StreamCommandHandler) error {
+	err := container.Invoke(func(h abstractions.StreamCommandHandler, reader common.ConfigReader, printer output.Printer, progress *output.ProgressView, prompter *prompt.Prompter) error {
 		if h != nil {
+			namespace, err := reader.ReadNamespace()
+			if err != nil {
+				return err
+			}
 			duration, err := time.ParseDuration(r.Deadline)
 			if err != nil {
 				return fmt.Errorf("failed to parse deadline %s: %w", r.Deadline, err)
 			}
+			if r.Id == "" {
+				return r.runBulk(h, printer, namespace, r.Class, func(stream *models.StreamSummary) (*models.OperationResult, error) {
+					ctx, cancel := context.WithTimeout(context.Background(), duration)
+					defer cancel()
+					return h.Restart(ctx, stream.Name, namespace, stream.StreamClass, r.operationOptions(r.Wait))
+				})
+			}
 			ctx, cancel := context.WithTimeout(context.Background(), duration)
 			defer cancel()
-			return h.Restart(ctx, r.Id, r.Wait)
+			options := r.operationOptions(r.Wait)
+			if r.Wait && !options.DryRun.IsDryRun() {
+				options.Progress = progress.Update
+			}
+			result, err := withStreamClass(prompter, r.Class, func(streamClass string) (*models.OperationResult, error) {
+				return h.Restart(ctx, r.Id, namespace, streamClass, options)
+			})
+			progress.Clear()
+			if err != nil {
+				return err
+			}
+			return printer.Print(result)
 		}
 		return fmt.Errorf("no handler provided for resuming stream")
 	})
 	return err
 }
 
+// Represents the command to read the stream logs.
+type LogsCmd struct {
+	Id            string        `arg:"" help:"The ID of the stream."`
+	Follow        bool          `short:"f" help:"Follow the logs across pod restarts and new job generations."`
+	Since         time.Duration `help:"Only return logs newer than a relative duration like 5s, 2m, or 3h."`
+	Previous      bool          `short:"p" help:"Print the logs of the previous container instances."`
+	AllContainers bool          `help:"Print the logs of all containers in the pods."`
+	Tail          int64         `help:"Lines of the recent logs to print for each container, all lines if zero."`
+}
+
+func (r *LogsCmd) Run(container *dig.Container) error {
+	err := container.Invoke(func(h abstractions.StreamCommandHandler, reader common.ConfigReader) error {
+		if h != nil {
+			namespace, err := reader.ReadNamespace()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+			options := models.LogOptions{Follow: r.Follow, Since: r.Since, Previous: r.Previous, AllContainers: r.AllContainers, TailLines: r.Tail}
+			return h.Logs(ctx, r.Id, namespace, options, os.Stdout)
+		}
+		return fmt.Errorf("no handler provided for reading stream logs")
+	})
+	return err
+}
+
+// Represents the command to show the stream events timeline.
+type EventsCmd struct {
+	Id    string `arg:"" help:"The ID of the stream."`
+	Class string `help:"The class of the stream, discovered from the stream ID if not provided."`
+}
+
+func (r *EventsCmd) Run(container *dig.Container) error {
+	err := container.Invoke(func(h abstractions.StreamCommandHandler, reader common.ConfigReader, printer output.Printer, prompter *prompt.Prompter) error {
+		if h != nil {
+			namespace, err := reader.ReadNamespace()
+			if err != nil {
+				return err
+			}
+			timeline, err := withStreamClass(prompter, r.Class, func(streamClass string) (*models.StreamTimeline, error) {
+				return h.Events(context.Background(), r.Id, namespace, streamClass)
+			})
+			if err != nil {
+				return err
+			}
+			return printer.Print(timeline)
+		}
+		return fmt.Errorf("no handler provided for reading stream events")
+	})
+	return err
+}
+
 // The Stream interaction commmands.
 type StreamCmd struct {
-	Suspend  SuspendCmd  `cmd:"" help:"Suspends the given stream."`
-	Resume   ResumeCmd   `cmd:"" help:"Resumes the given stream."`
-	Backfill BackfillCmd `cmd:"" help:"Restarts the given stream in the backfill mode."`
-	Restart  RestartCmd  `cmd:"" help:"Restarts the given stream in the streaming mode."`
+	List     ListCmd     `cmd:"" help:"Lists the streams of all stream classes."`
+	Status   StatusCmd   `cmd:"" help:"Shows the state of the given stream and its job."`
+	Describe DescribeCmd `cmd:"" help:"Shows the spec of the given stream and the objects it references."`
+	Create   CreateCmd   `cmd:"" help:"Creates a stream of the given stream class, suspended unless --running is set."`
+	Set      SetCmd      `cmd:"" help:"Changes the spec fields of the given stream."`
+	Edit     EditCmd     `cmd:"" help:"Edits the manifest of the given stream in the editor set by KUBE_EDITOR or EDITOR."`
+	Clone    CloneCmd    `cmd:"" aliases:"copy" help:"Copies the given stream under a new name, the copy is created suspended."`
+	Export   ExportCmd   `cmd:"" help:"Exports the given or the selected streams with their stream classes and job templates as a bundle."`
+	Import   ImportCmd   `cmd:"" help:"Creates or updates the streams, stream classes and job templates of the bundle."`
+	Diff     DiffCmd     `cmd:"" help:"Compares the stream manifest with the live stream, exits with 1 if they differ."`
+	Delete   DeleteCmd   `cmd:"" help:"Suspends the given stream, waits for its job to stop and deletes the stream."`
+	Suspend  SuspendCmd  `cmd:"" help:"Suspends the given stream or the selected streams."`
+	Resume   ResumeCmd   `cmd:"" help:"Resumes the given stream or the selected streams."`
+	Backfill BackfillCmd `cmd:"" help:"Restarts the given stream or the selected streams in the backfill mode."`
+	Restart  RestartCmd  `cmd:"" help:"Restarts the given stream or the selected streams in the streaming mode."`
+	Logs     LogsCmd     `cmd:"" help:"Prints the logs of the given stream job pods."`
+	Events   EventsCmd   `cmd:"" aliases:"timeline" help:"Shows the timeline of the events of the given stream, its job and pods."`
 }
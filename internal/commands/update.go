@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/client/api/common"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"s-vitaliy/kubectl-plugin-arcane/internal/output"
+	"s-vitaliy/kubectl-plugin-arcane/internal/prompt"
+	"strings"
+	"time"
+
+	"go.uber.org/dig"
+)
+
+// UpdateFlags adds the flags shared by the commands changing the stream spec.
+type UpdateFlags struct {
+	Class    string `help:"The class of the stream, discovered from the stream ID if not provided."`
+	Restart  bool   `help:"Restart the running stream after the change, so the operator starts the job with the new spec."`
+	Wait     bool   `help:"Wait for the restarted stream to run."`
+	Deadline string `help:"The deadline for the restart of the stream." default:"1m"`
+	OperationFlags
+}
+
+// runUpdate runs the update of the stream spec with the options built from the flags and prints the result.
+func (f *UpdateFlags) runUpdate(container *dig.Container, update func(h abstractions.StreamCommandHandler, ctx context.Context, namespace string, streamClass string, options models.UpdateOptions) (*models.OperationResult, error)) error {
+	err := container.Invoke(func(h abstractions.StreamCommandHandler, reader common.ConfigReader, printer output.Printer, progress *output.ProgressView, prompter *prompt.Prompter) error {
+		if h != nil {
+			namespace, err := reader.ReadNamespace()
+			if err != nil {
+				return err
+			}
+			duration, err := time.ParseDuration(f.Deadline)
+			if err != nil {
+				return fmt.Errorf("failed to parse deadline %s: %w", f.Deadline, err)
+			}
+			options := models.UpdateOptions{OperationOptions: f.operationOptions(f.Wait), Restart: f.Restart, RestartDeadline: duration}
+			if f.Restart && f.Wait && !options.DryRun.IsDryRun() {
+				options.Progress = progress.Update
+			}
+			result, err := withStreamClass(prompter, f.Class, func(streamClass string) (*models.OperationResult, error) {
+				return update(h, context.Background(), namespace, streamClass, options)
+			})
+			progress.Clear()
+			if err != nil {
+				return err
+			}
+			return printer.Print(result)
+		}
+		return fmt.Errorf("no handler provided for updating stream")
+	})
+	return err
+}
+
+// Represents the command to set the fields of the stream spec.
+type SetCmd struct {
+	Id     string   `arg:"" help:"The ID of the stream."`
+	Values []string `arg:"" name:"path=value" help:"The new values of the spec fields, e.g. sourceSettings.fetchSize=5000. The paths are relative to the stream spec, the array items are addressed by their indexes."`
+	UpdateFlags
+}
+
+func (r *SetCmd) Validate() error {
+	_, err := r.fieldValues()
+	return err
+}
+
+func (r *SetCmd) Run(container *dig.Container) error {
+	values, err := r.fieldValues()
+	if err != nil {
+		return err
+	}
+	return r.runUpdate(container, func(h abstractions.StreamCommandHandler, ctx context.Context, namespace string, streamClass string, options models.UpdateOptions) (*models.OperationResult, error) {
+		return h.Set(ctx, r.Id, namespace, streamClass, values, options)
+	})
+}
+
+func (r *SetCmd) fieldValues() ([]models.FieldValue, error) {
+	return parseFieldValues(r.Values)
+}
+
+// parseFieldValues splits the path=value assignments given on the command line.
+func parseFieldValues(assignments []string) ([]models.FieldValue, error) {
+	values := make([]models.FieldValue, 0, len(assignments))
+	for _, assignment := range assignments {
+		path, value, found := strings.Cut(assignment, "=")
+		if !found || path == "" {
+			return nil, fmt.Errorf("invalid field value %q, expected path=value", assignment)
+		}
+		values = append(values, models.FieldValue{Path: path, Value: value})
+	}
+	return values, nil
+}
+
+// Represents the command to edit the stream manifest in the editor.
+type EditCmd struct {
+	Id string `arg:"" help:"The ID of the stream."`
+	UpdateFlags
+}
+
+func (r *EditCmd) Run(container *dig.Container) error {
+	return r.runUpdate(container, func(h abstractions.StreamCommandHandler, ctx context.Context, namespace string, streamClass string, options models.UpdateOptions) (*models.OperationResult, error) {
+		return h.Edit(ctx, r.Id, namespace, streamClass, func(manifest string) (string, error) {
+			return prompt.Edit(r.Id, manifest)
+		}, options)
+	})
+}
@@ -0,0 +1,130 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around every change.
+const contextLines = 3
+
+type operation int
+
+const (
+	opEqual operation = iota
+	opInsert
+	opDelete
+)
+
+type line struct {
+	operation operation
+	text      string
+	fromIndex int
+	toIndex   int
+}
+
+// Unified returns the unified diff of two texts, or an empty string if the texts are equal.
+func Unified(fromName string, toName string, from string, to string) string {
+	if from == to {
+		return ""
+	}
+	lines := compare(splitLines(from), splitLines(to))
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "--- %s\n+++ %s\n", fromName, toName)
+	for _, hunk := range hunks(lines) {
+		writeHunk(&builder, lines[hunk[0]:hunk[1]])
+	}
+	return builder.String()
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// compare builds the edit script from the longest common subsequence of the lines.
+func compare(from []string, to []string) []line {
+	lengths := make([][]int, len(from)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]line, 0, len(from)+len(to))
+	i, j := 0, 0
+	for i < len(from) || j < len(to) {
+		switch {
+		case i < len(from) && j < len(to) && from[i] == to[j]:
+			lines = append(lines, line{opEqual, from[i], i, j})
+			i++
+			j++
+		case i < len(from) && (j == len(to) || lengths[i+1][j] >= lengths[i][j+1]):
+			lines = append(lines, line{opDelete, from[i], i, j})
+			i++
+		default:
+			lines = append(lines, line{opInsert, to[j], i, j})
+			j++
+		}
+	}
+	return lines
+}
+
+// hunks returns the [start, end) ranges of the edit script containing the changes with their context.
+func hunks(lines []line) [][2]int {
+	var ranges [][2]int
+	for i, current := range lines {
+		if current.operation == opEqual {
+			continue
+		}
+		start := max(i-contextLines, 0)
+		end := min(i+contextLines+1, len(lines))
+		if len(ranges) > 0 && start <= ranges[len(ranges)-1][1] {
+			ranges[len(ranges)-1][1] = end
+		} else {
+			ranges = append(ranges, [2]int{start, end})
+		}
+	}
+	return ranges
+}
+
+func writeHunk(builder *strings.Builder, lines []line) {
+	fromStart, toStart := lines[0].fromIndex+1, lines[0].toIndex+1
+	fromCount, toCount := 0, 0
+	for _, current := range lines {
+		if current.operation != opInsert {
+			fromCount++
+		}
+		if current.operation != opDelete {
+			toCount++
+		}
+	}
+	if fromCount == 0 {
+		fromStart--
+	}
+	if toCount == 0 {
+		toStart--
+	}
+
+	fmt.Fprintf(builder, "@@ -%d,%d +%d,%d @@\n", fromStart, fromCount, toStart, toCount)
+	for _, current := range lines {
+		switch current.operation {
+		case opEqual:
+			builder.WriteString(" " + current.text + "\n")
+		case opInsert:
+			builder.WriteString("+" + current.text + "\n")
+		case opDelete:
+			builder.WriteString("-" + current.text + "\n")
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package models
+
+import "fmt"
+
+// BulkOperationResult is the result of an operation applied to several streams.
+type BulkOperationResult struct {
+	Items []*BulkOperationItem `json:"items"`
+}
+
+// BulkOperationItem is the outcome of the bulk operation for a single stream.
+type BulkOperationItem struct {
+	Name        string           `json:"name"`
+	StreamClass string           `json:"streamClass,omitempty"`
+	Result      *OperationResult `json:"result,omitempty"`
+	Error       string           `json:"error,omitempty"`
+}
+
+// Failed returns the number of streams the operation failed for.
+func (r *BulkOperationResult) Failed() int {
+	failed := 0
+	for _, item := range r.Items {
+		if item.Error != "" {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Err returns an error if the operation failed for any stream.
+func (r *BulkOperationResult) Err() error {
+	if failed := r.Failed(); failed > 0 {
+		return fmt.Errorf("operation failed for %d of %d streams", failed, len(r.Items))
+	}
+	return nil
+}
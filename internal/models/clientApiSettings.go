@@ -28,6 +28,11 @@ func (settings *ClientApiSettings) ToGroupVersionResource() schema.GroupVersionR
 	}
 }
 
+// ResourceName returns the fully qualified resource name in the plural.version.group form.
+func (settings *ClientApiSettings) ResourceName() string {
+	return fmt.Sprintf("%s.%s.%s", settings.apiPlural, settings.apiVersion, settings.apiGroup)
+}
+
 func FromJobAnnotations(annotations map[string]interface{}) (*ClientApiSettings, error) {
 	apiGroup, err := getAnnotation(annotations, "stream.arcane.sneaksanddata.com/api-group")
 	if err != nil {
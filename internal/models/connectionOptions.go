@@ -0,0 +1,13 @@
+package models
+
+// ConnectionOptions holds the standard kubectl flags used to connect to the cluster.
+type ConnectionOptions struct {
+	// Kubeconfig is the path to the kubeconfig file, empty to use $KUBECONFIG or the default location.
+	Kubeconfig string
+
+	// Context is the name of the kubeconfig context, empty to use the current context.
+	Context string
+
+	// Namespace is the namespace of the streams, empty to use the context namespace.
+	Namespace string
+}
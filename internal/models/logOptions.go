@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// LogOptions defines which logs of the stream job pods are read.
+type LogOptions struct {
+	// Follow keeps streaming the logs, including the pods created after restarts and new job generations.
+	Follow bool
+
+	// Since limits the logs to the entries newer than the duration, zero for all logs.
+	Since time.Duration
+
+	// Previous reads the logs of the previous container instances.
+	Previous bool
+
+	// AllContainers reads the logs of all containers instead of the first one.
+	AllContainers bool
+
+	// TailLines limits the logs to the last lines of each container, zero for all logs.
+	TailLines int64
+}
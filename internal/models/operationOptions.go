@@ -0,0 +1,127 @@
+package models
+
+import "time"
+
+// DryRunMode defines whether the changes are only previewed instead of applied.
+type DryRunMode string
+
+const (
+	// DryRunNone applies the changes.
+	DryRunNone DryRunMode = "none"
+
+	// DryRunClient prints the changes without sending them to the API server.
+	DryRunClient DryRunMode = "client"
+
+	// DryRunServer sends the changes to the API server without persisting them.
+	DryRunServer DryRunMode = "server"
+)
+
+// IsDryRun returns true if the changes must not be persisted.
+func (mode DryRunMode) IsDryRun() bool {
+	return mode == DryRunClient || mode == DryRunServer
+}
+
+// OperationOptions holds the options of the commands that change the stream state.
+type OperationOptions struct {
+	// Wait defines whether the command waits for the stream to reach the target phase.
+	Wait bool
+
+	// DryRun defines whether the changes are only previewed.
+	DryRun DryRunMode
+
+	// Force applies the operation even if the pre-flight validation rejects it or finds nothing to change.
+	Force bool
+
+	// Progress, if set, receives the progress of the operation while it waits for the stream.
+	Progress ProgressFunc
+}
+
+// CreateOptions holds the options of the commands that create streams.
+type CreateOptions struct {
+	// DryRun defines whether the stream is only previewed.
+	DryRun DryRunMode
+
+	// Running creates the stream without the suspended state, so the operator starts it right away.
+	Running bool
+}
+
+// UpdateOptions holds the options of the commands that change the stream spec.
+type UpdateOptions struct {
+	OperationOptions
+
+	// Restart restarts the running stream after the change, so the operator starts the job with the new spec.
+	// The Wait, Force and Progress options apply to the restart.
+	Restart bool
+
+	// RestartDeadline limits the duration of the restart, it is not limited if zero.
+	// The deadline starts after the change, so it does not include the time spent in the editor.
+	RestartDeadline time.Duration
+}
+
+// FieldValue is the new value of the stream spec field given on the command line.
+type FieldValue struct {
+	// Path is the dot-separated path of the field relative to the stream spec, e.g. sourceSettings.fetchSize.
+	Path string
+
+	// Value is the text of the value, converted to the type of the field by the stream schema.
+	Value string
+}
+
+// EditFunc receives the stream manifest as yaml and returns the edited manifest.
+type EditFunc func(manifest string) (string, error)
+
+// CascadeMode defines what happens to the resources owned by the deleted stream.
+type CascadeMode string
+
+const (
+	// CascadeBackground deletes the stream right away and the owned resources in the background.
+	CascadeBackground CascadeMode = "background"
+
+	// CascadeForeground deletes the owned resources before the stream.
+	CascadeForeground CascadeMode = "foreground"
+
+	// CascadeOrphan keeps the owned resources.
+	CascadeOrphan CascadeMode = "orphan"
+)
+
+// DeleteOptions holds the options of the commands that delete streams.
+// The Wait option waits for the stream resource to be removed and the Force option deletes the stream right away,
+// without suspending it and waiting for its job to be removed first.
+type DeleteOptions struct {
+	OperationOptions
+
+	// Cascade defines what happens to the resources owned by the stream.
+	Cascade CascadeMode
+}
+
+// CloneOptions holds the options of the commands that copy streams.
+type CloneOptions struct {
+	// Name is the name of the new stream.
+	Name string
+
+	// Namespace is the namespace of the new stream, the namespace of the source stream if empty.
+	Namespace string
+
+	// Values override the spec fields of the copy.
+	Values []FieldValue
+
+	// DryRun defines whether the new stream is only previewed.
+	DryRun DryRunMode
+}
+
+// ImportOptions holds the options of the commands that import stream bundles.
+type ImportOptions struct {
+	// DryRun defines whether the changes are only previewed.
+	DryRun DryRunMode
+
+	// KeepState applies the stream states recorded in the bundle, so the streams suspended at the export stay suspended
+	// and the others run. Otherwise the new streams are created suspended and the state of the existing streams is kept.
+	KeepState bool
+}
+
+// DiffOptions holds the options of the commands that compare the streams with the manifests.
+type DiffOptions struct {
+	// IncludeState compares the arcane/state annotation, which is ignored otherwise,
+	// since the state is changed by the suspend and resume commands rather than by the manifests.
+	IncludeState bool
+}
@@ -0,0 +1,31 @@
+package models
+
+// OperationResult is the result of a command that changes the stream state.
+type OperationResult struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Operation string `json:"operation"`
+
+	// Phase is the phase the stream reached, empty if the command did not wait for the stream.
+	Phase string `json:"phase,omitempty"`
+
+	// Message explains why the stream was left unchanged.
+	Message string `json:"message,omitempty"`
+
+	// Patches are the patches previewed in the dry run mode.
+	Patches []*PatchResult `json:"patches,omitempty"`
+
+	// DryRun is the dry run mode of the operations that send whole objects instead of patches.
+	DryRun DryRunMode `json:"dryRun,omitempty"`
+
+	// Manifest is the stream resource previewed in the dry run mode.
+	Manifest string `json:"manifest,omitempty"`
+
+	// Diff is the unified diff of the stream resource changed by the command.
+	Diff string `json:"diff,omitempty"`
+}
+
+// NewOperationResult creates a result of the operation on the stream.
+func NewOperationResult(name string, namespace string, operation string) *OperationResult {
+	return &OperationResult{Name: name, Namespace: namespace, Operation: operation}
+}
@@ -0,0 +1,20 @@
+package models
+
+// PatchResult describes a patch applied, or previewed in the dry run mode, to a stream.
+type PatchResult struct {
+	DryRun   DryRunMode `json:"dryRun,omitempty"`
+	Resource string     `json:"resource"`
+	Name     string     `json:"name"`
+	Patch    string     `json:"patch"`
+
+	// Diff is the difference between the live object and the patched object returned by the server dry run.
+	Diff string `json:"diff,omitempty"`
+
+	// ResourceVersion of the stream as returned by the applied patch.
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+
+	// State and Phase are the arcane/state annotation and the status phase of the patched stream,
+	// the operator has reacted to the patch once either of them changes.
+	State string `json:"-"`
+	Phase string `json:"-"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// StreamBundle is the set of streams exported together with the stream classes and the job templates they reference.
+// The objects have no namespace and no fields maintained by the cluster, so the bundle can be imported anywhere.
+type StreamBundle struct {
+	Objects []*unstructured.Unstructured `json:"items"`
+}
+
+// BundleResult is the outcome of the bundle export or import for every object of the bundle.
+type BundleResult struct {
+	Items []*BundleItem `json:"items"`
+
+	// DryRun is the dry run mode of the import.
+	DryRun DryRunMode `json:"dryRun,omitempty"`
+}
+
+// BundleItem is the outcome of the bundle operation for a single object.
+type BundleItem struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Operation string `json:"operation,omitempty"`
+	Message   string `json:"message,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Failed returns the number of objects the operation failed for.
+func (r *BundleResult) Failed() int {
+	failed := 0
+	for _, item := range r.Items {
+		if item.Error != "" {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Err returns an error if the operation failed for any object.
+func (r *BundleResult) Err() error {
+	if failed := r.Failed(); failed > 0 {
+		return fmt.Errorf("operation failed for %d of %d objects", failed, len(r.Items))
+	}
+	return nil
+}
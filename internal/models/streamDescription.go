@@ -0,0 +1,96 @@
+package models
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	ReferenceKindSecret      = "Secret"
+	ReferenceKindJobTemplate = "StreamingJobTemplate"
+	ReferenceKindStreamClass = "StreamClass"
+)
+
+// StreamDescription is the stream custom resource spec together with the objects it references.
+type StreamDescription struct {
+	StreamSummary
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Spec        map[string]any    `json:"spec,omitempty"`
+	References  []StreamReference `json:"references"`
+}
+
+// StreamReference is an object referenced by the stream and the result of its resolution.
+type StreamReference struct {
+	// Field is the path of the reference in the stream resource, empty for the stream class.
+	Field string `json:"field,omitempty"`
+	Kind  string `json:"kind"`
+	Name  string `json:"name"`
+
+	// Key is the secret key selected by the reference, if any.
+	Key string `json:"key,omitempty"`
+
+	// Keys are the keys of the referenced secret. The values are never read.
+	Keys []string `json:"keys,omitempty"`
+
+	// Problem describes why the reference is broken, it is empty if the referenced object is resolved.
+	Problem string `json:"problem,omitempty"`
+}
+
+// IsBroken checks whether the referenced object could not be resolved.
+func (r StreamReference) IsBroken() bool {
+	return r.Problem != ""
+}
+
+// BrokenReferences returns the references that could not be resolved.
+func (d *StreamDescription) BrokenReferences() []StreamReference {
+	var broken []StreamReference
+	for _, reference := range d.References {
+		if reference.IsBroken() {
+			broken = append(broken, reference)
+		}
+	}
+	return broken
+}
+
+// DescriptionFromStreamObject reads the stream description from the stream custom resource.
+// The references are read from the spec, but not resolved.
+func DescriptionFromStreamObject(stream *unstructured.Unstructured) *StreamDescription {
+	spec, _, _ := unstructured.NestedMap(stream.Object, "spec")
+	annotations := stream.GetAnnotations()
+	delete(annotations, LastAppliedAnnotation)
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	return &StreamDescription{
+		StreamSummary: *FromStreamObject(stream),
+		Labels:        stream.GetLabels(),
+		Annotations:   annotations,
+		Spec:          spec,
+		References:    referencesFromSpec(spec),
+	}
+}
+
+// referencesFromSpec reads the connection secret and the job template references from the stream spec.
+func referencesFromSpec(spec map[string]any) []StreamReference {
+	var references []StreamReference
+	if secret, ok := spec["connectionStringRef"].(map[string]any); ok {
+		references = append(references, StreamReference{
+			Field: "spec.connectionStringRef",
+			Kind:  ReferenceKindSecret,
+			Name:  nestedString(secret, "name"),
+			Key:   nestedString(secret, "key"),
+		})
+	}
+	for _, field := range []string{"jobTemplateRef", "backfillJobTemplateRef"} {
+		template, ok := spec[field].(map[string]any)
+		if !ok {
+			continue
+		}
+		references = append(references, StreamReference{
+			Field: "spec." + field,
+			Kind:  ReferenceKindJobTemplate,
+			Name:  nestedString(template, "name"),
+		})
+	}
+	return references
+}
@@ -0,0 +1,22 @@
+package models
+
+// StreamDiff is the difference between the live stream custom resource and the local manifest.
+type StreamDiff struct {
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace"`
+	StreamClass string `json:"streamClass,omitempty"`
+
+	// Exists is false if the stream is not created yet, the diff then adds the whole manifest.
+	Exists bool `json:"exists"`
+
+	// Diff is the unified diff from the live stream to the manifest, empty if they do not differ.
+	Diff string `json:"diff,omitempty"`
+
+	// Color highlights the added and removed lines of the diff in the text output.
+	Color bool `json:"-"`
+}
+
+// IsDifferent checks whether the live stream differs from the manifest.
+func (d *StreamDiff) IsDifferent() bool {
+	return d.Diff != ""
+}
@@ -0,0 +1,10 @@
+package models
+
+// StreamFilter selects the streams returned by the list operations.
+type StreamFilter struct {
+	// StreamClass limits the streams to a single stream class, empty for all stream classes.
+	StreamClass string
+
+	// LabelSelector is a label query over the streams, empty to select all streams.
+	LabelSelector string
+}
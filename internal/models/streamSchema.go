@@ -0,0 +1,26 @@
+package models
+
+// StreamSchema is the OpenAPI v3 schema of the stream custom resource served by a stream class.
+type StreamSchema struct {
+	ApiVersion string         `json:"apiVersion"`
+	Kind       string         `json:"kind"`
+	Schema     map[string]any `json:"schema"`
+}
+
+// SchemaField is a leaf field of the stream schema.
+type SchemaField struct {
+	// Path is the dot-separated path of the field in the stream resource, e.g. spec.sourceSettings.schema.
+	Path        string `json:"path"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+	Enum        []any  `json:"enum,omitempty"`
+}
+
+// StreamTemplate is the skeleton of a new stream built from the stream schema.
+type StreamTemplate struct {
+	// Manifest is the stream resource with the required fields set to the default or zero values.
+	Manifest map[string]any `json:"manifest"`
+
+	// Fields are the required fields without default values, the values the user has to provide.
+	Fields []SchemaField `json:"fields,omitempty"`
+}
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// StreamStatus is the full state of a stream and its backing job.
+type StreamStatus struct {
+	StreamSummary
+	Message    string            `json:"message,omitempty"`
+	Conditions []StreamCondition `json:"conditions,omitempty"`
+	Job        *JobStatus        `json:"job,omitempty"`
+}
+
+// StreamCondition is a single entry of the stream status conditions.
+type StreamCondition struct {
+	Type               string `json:"type"`
+	Status             string `json:"status"`
+	Reason             string `json:"reason,omitempty"`
+	Message            string `json:"message,omitempty"`
+	LastTransitionTime string `json:"lastTransitionTime,omitempty"`
+}
+
+// JobStatus is the state of the batch/v1 Job running the stream.
+type JobStatus struct {
+	Name      string `json:"name"`
+	StartTime string `json:"startTime,omitempty"`
+	Active    int64  `json:"active"`
+	Succeeded int64  `json:"succeeded"`
+	Failed    int64  `json:"failed"`
+
+	// FailureReason is the reason and message of the Failed condition of the job, if any.
+	FailureReason string      `json:"failureReason,omitempty"`
+	Pods          []PodStatus `json:"pods,omitempty"`
+}
+
+// PodStatus is the state of a single pod of the stream job.
+type PodStatus struct {
+	Name      string `json:"name"`
+	Phase     string `json:"phase"`
+	Restarts  int64  `json:"restarts"`
+	StartTime string `json:"startTime,omitempty"`
+}
+
+// StatusFromStreamObject reads the stream status from the stream custom resource.
+func StatusFromStreamObject(stream *unstructured.Unstructured) *StreamStatus {
+	status := &StreamStatus{
+		StreamSummary: *FromStreamObject(stream),
+		Message:       nestedString(stream.Object, "status", "message"),
+	}
+	conditions, _, _ := unstructured.NestedSlice(stream.Object, "status", "conditions")
+	for _, value := range conditions {
+		condition, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		status.Conditions = append(status.Conditions, StreamCondition{
+			Type:               nestedString(condition, "type"),
+			Status:             nestedString(condition, "status"),
+			Reason:             nestedString(condition, "reason"),
+			Message:            nestedString(condition, "message"),
+			LastTransitionTime: nestedString(condition, "lastTransitionTime"),
+		})
+	}
+	return status
+}
+
+// FromJobObject reads the job status from the batch/v1 Job object.
+func FromJobObject(job *unstructured.Unstructured) *JobStatus {
+	return &JobStatus{
+		Name:      job.GetName(),
+		StartTime: nestedString(job.Object, "status", "startTime"),
+		Active:    nestedInt64(job.Object, "status", "active"),
+		Succeeded: nestedInt64(job.Object, "status", "succeeded"),
+		Failed:    nestedInt64(job.Object, "status", "failed"),
+
+		FailureReason: jobFailureReason(job),
+	}
+}
+
+// IsTerminal checks whether the condition reports that the stream has failed.
+func (c StreamCondition) IsTerminal() bool {
+	return (strings.EqualFold(c.Type, "Failed") || strings.EqualFold(c.Type, "Error")) && strings.EqualFold(c.Status, "True")
+}
+
+// TerminalConditions returns the conditions reporting that the stream has failed.
+func (s *StreamStatus) TerminalConditions() []StreamCondition {
+	var terminal []StreamCondition
+	for _, condition := range s.Conditions {
+		if condition.IsTerminal() {
+			terminal = append(terminal, condition)
+		}
+	}
+	return terminal
+}
+
+func jobFailureReason(job *unstructured.Unstructured) string {
+	conditions, _, _ := unstructured.NestedSlice(job.Object, "status", "conditions")
+	for _, value := range conditions {
+		condition, ok := value.(map[string]any)
+		if !ok || nestedString(condition, "type") != "Failed" || nestedString(condition, "status") != "True" {
+			continue
+		}
+		reason := nestedString(condition, "reason")
+		if message := nestedString(condition, "message"); message != "" {
+			reason += ": " + message
+		}
+		return reason
+	}
+	return ""
+}
+
+// FromPodObject reads the pod status from the v1 Pod object.
+func FromPodObject(pod *unstructured.Unstructured) PodStatus {
+	var restarts int64
+	containerStatuses, _, _ := unstructured.NestedSlice(pod.Object, "status", "containerStatuses")
+	for _, value := range containerStatuses {
+		if containerStatus, ok := value.(map[string]any); ok {
+			restarts += nestedInt64(containerStatus, "restartCount")
+		}
+	}
+	return PodStatus{
+		Name:      pod.GetName(),
+		Phase:     nestedString(pod.Object, "status", "phase"),
+		Restarts:  restarts,
+		StartTime: nestedString(pod.Object, "status", "startTime"),
+	}
+}
+
+func nestedString(object map[string]any, fields ...string) string {
+	value, _, _ := unstructured.NestedString(object, fields...)
+	return value
+}
+
+func nestedInt64(object map[string]any, fields ...string) int64 {
+	value, _, _ := unstructured.NestedInt64(object, fields...)
+	return value
+}
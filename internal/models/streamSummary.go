@@ -0,0 +1,38 @@
+package models
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// StateAnnotation is the annotation used by the Arcane operator to control the stream state.
+const StateAnnotation = "arcane/state"
+
+// LastAppliedAnnotation is the annotation kubectl apply stores the applied manifest in.
+const LastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// StreamSummary is a short description of a stream custom resource.
+type StreamSummary struct {
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace"`
+	StreamClass string `json:"streamClass,omitempty"`
+	Kind        string `json:"kind"`
+	Phase       string `json:"phase,omitempty"`
+	State       string `json:"state,omitempty"`
+}
+
+// StreamList is a list of stream summaries.
+type StreamList struct {
+	Items []*StreamSummary `json:"items"`
+}
+
+// FromStreamObject reads the stream summary from the stream custom resource.
+func FromStreamObject(stream *unstructured.Unstructured) *StreamSummary {
+	phase, _, _ := unstructured.NestedString(stream.Object, "status", "phase")
+	return &StreamSummary{
+		Name:      stream.GetName(),
+		Namespace: stream.GetNamespace(),
+		Kind:      stream.GetKind(),
+		Phase:     phase,
+		State:     stream.GetAnnotations()[StateAnnotation],
+	}
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// TimelineEntryCondition is the type of the timeline entries created from the stream status conditions.
+const TimelineEntryCondition = "Condition"
+
+// StreamTimeline is the chronological history of a stream, its job and the job pods.
+type StreamTimeline struct {
+	Name      string          `json:"name"`
+	Namespace string          `json:"namespace"`
+	Entries   []TimelineEntry `json:"entries"`
+}
+
+// TimelineEntry is a single Kubernetes event or stream condition transition.
+type TimelineEntry struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Object  string    `json:"object"`
+	Reason  string    `json:"reason,omitempty"`
+	Message string    `json:"message,omitempty"`
+	Count   int32     `json:"count,omitempty"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// WaitProgress is a snapshot of a stream operation waiting for the stream to reach a phase.
+type WaitProgress struct {
+	Name        string
+	Namespace   string
+	Operation   string
+	TargetPhase string
+	Phase       string
+
+	// Started is the time the operation started.
+	Started time.Time
+
+	// Deadline is the time the operation is cancelled at, zero if there is no deadline.
+	Deadline time.Time
+
+	// Job is the state of the stream job, nil if the job does not exist.
+	Job *JobStatus
+
+	// LogLines are the last lines of the logs of the stream job pods.
+	LogLines []string
+}
+
+// ProgressFunc receives the progress of a stream operation while it waits for the stream.
+type ProgressFunc func(progress *WaitProgress)
@@ -0,0 +1,42 @@
+package output
+
+import "strings"
+
+// The ANSI escape sequences of the diff colors.
+const (
+	colorReset = "\x1b[0m"
+	colorBold  = "\x1b[1m"
+	colorRed   = "\x1b[31m"
+	colorGreen = "\x1b[32m"
+	colorCyan  = "\x1b[36m"
+)
+
+// colorDiff highlights the unified diff: the file headers in bold, the hunk headers in cyan,
+// the removed lines in red and the added lines in green.
+func colorDiff(text string) string {
+	lines := strings.SplitAfter(text, "\n")
+	var builder strings.Builder
+	for _, line := range lines {
+		color := ""
+		switch {
+		case strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "+++ "):
+			color = colorBold
+		case strings.HasPrefix(line, "@@"):
+			color = colorCyan
+		case strings.HasPrefix(line, "-"):
+			color = colorRed
+		case strings.HasPrefix(line, "+"):
+			color = colorGreen
+		}
+		if color == "" {
+			builder.WriteString(line)
+			continue
+		}
+		content, newline := strings.CutSuffix(line, "\n")
+		builder.WriteString(color + content + colorReset)
+		if newline {
+			builder.WriteString("\n")
+		}
+	}
+	return builder.String()
+}
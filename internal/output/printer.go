@@ -0,0 +1,174 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	FormatTable      = "table"
+	FormatWide       = "wide"
+	FormatJson       = "json"
+	FormatYaml       = "yaml"
+	FormatName       = "name"
+	FormatJsonPath   = "jsonpath"
+	FormatGoTemplate = "go-template"
+)
+
+// Printer renders the command results.
+type Printer interface {
+	Print(result any) error
+}
+
+// NewPrinter creates a printer for the output format in the kubectl -o syntax:
+// table, wide, json, yaml, name, jsonpath=<template> or go-template=<template>.
+// The empty format selects the table output.
+func NewPrinter(format string, writer io.Writer) (Printer, error) {
+	name, argument, _ := strings.Cut(format, "=")
+	switch name {
+	case "", FormatTable:
+		return &textPrinter{writer: writer, wide: false}, nil
+	case FormatWide:
+		return &textPrinter{writer: writer, wide: true}, nil
+	case FormatJson:
+		return &jsonPrinter{writer: writer}, nil
+	case FormatYaml:
+		return &yamlPrinter{writer: writer}, nil
+	case FormatName:
+		return &namePrinter{writer: writer}, nil
+	case FormatJsonPath:
+		return newJsonPathPrinter(argument, writer)
+	case FormatGoTemplate:
+		return newGoTemplatePrinter(argument, writer)
+	default:
+		return nil, fmt.Errorf("unsupported output format %q, expected one of: table, wide, json, yaml, name, jsonpath=..., go-template=...", format)
+	}
+}
+
+type textPrinter struct {
+	writer io.Writer
+	wide   bool
+}
+
+func (p *textPrinter) Print(result any) error {
+	return writeText(p.writer, result, p.wide)
+}
+
+type jsonPrinter struct {
+	writer io.Writer
+}
+
+func (p *jsonPrinter) Print(result any) error {
+	data, err := json.MarshalIndent(result, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal result to json: %w", err)
+	}
+	_, err = fmt.Fprintln(p.writer, string(data))
+	return err
+}
+
+type yamlPrinter struct {
+	writer io.Writer
+}
+
+func (p *yamlPrinter) Print(result any) error {
+	data, err := yaml.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result to yaml: %w", err)
+	}
+	_, err = p.writer.Write(data)
+	return err
+}
+
+type namePrinter struct {
+	writer io.Writer
+}
+
+func (p *namePrinter) Print(result any) error {
+	for _, name := range resourceNames(result) {
+		if _, err := fmt.Fprintln(p.writer, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type jsonPathPrinter struct {
+	writer   io.Writer
+	template *jsonpath.JSONPath
+}
+
+func newJsonPathPrinter(expression string, writer io.Writer) (Printer, error) {
+	if expression == "" {
+		return nil, fmt.Errorf("jsonpath template must be specified, e.g. -o jsonpath='{.name}'")
+	}
+	if !strings.Contains(expression, "{") {
+		expression = "{" + expression + "}"
+	}
+	template := jsonpath.New("output").AllowMissingKeys(true)
+	if err := template.Parse(expression); err != nil {
+		return nil, fmt.Errorf("failed to parse jsonpath template %s: %w", expression, err)
+	}
+	return &jsonPathPrinter{writer: writer, template: template}, nil
+}
+
+func (p *jsonPathPrinter) Print(result any) error {
+	data, err := toGenericObject(result)
+	if err != nil {
+		return err
+	}
+	if err := p.template.Execute(p.writer, data); err != nil {
+		return fmt.Errorf("failed to execute jsonpath template: %w", err)
+	}
+	_, err = fmt.Fprintln(p.writer)
+	return err
+}
+
+type goTemplatePrinter struct {
+	writer   io.Writer
+	template *template.Template
+}
+
+func newGoTemplatePrinter(text string, writer io.Writer) (Printer, error) {
+	if text == "" {
+		return nil, fmt.Errorf("go-template must be specified, e.g. -o go-template='{{.name}}'")
+	}
+	parsed, err := template.New("output").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse go-template %s: %w", text, err)
+	}
+	return &goTemplatePrinter{writer: writer, template: parsed}, nil
+}
+
+func (p *goTemplatePrinter) Print(result any) error {
+	data, err := toGenericObject(result)
+	if err != nil {
+		return err
+	}
+	if err := p.template.Execute(p.writer, data); err != nil {
+		return fmt.Errorf("failed to execute go-template: %w", err)
+	}
+	return nil
+}
+
+// toGenericObject converts the result to maps and slices, so templates address fields by their json names.
+func toGenericObject(result any) (any, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result to json: %w", err)
+	}
+	var object any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&object); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+	return object, nil
+}
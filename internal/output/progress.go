@@ -0,0 +1,135 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+)
+
+// ProgressView renders the progress of the stream operations waiting for the stream.
+// On a terminal the progress is redrawn in place and the text written through the view, like the logs,
+// is printed above it. Otherwise every change of the progress is printed as a single line.
+type ProgressView struct {
+	mu     sync.Mutex
+	writer io.Writer
+	width  int
+
+	// frame is the number of lines drawn on the terminal.
+	frame int
+
+	// lastSummary is the last progress printed in the line mode.
+	lastSummary string
+
+	now func() time.Time
+}
+
+// NewProgressView creates a view writing to the writer. A positive width is the width of the terminal
+// the writer is attached to and enables the in-place rendering, zero selects the line mode.
+func NewProgressView(writer io.Writer, width int) *ProgressView {
+	return &ProgressView{writer: writer, width: width, now: time.Now}
+}
+
+// Update renders the progress.
+func (v *ProgressView) Update(progress *models.WaitProgress) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.width <= 0 {
+		summary := progressSummary(progress)
+		if summary == v.lastSummary {
+			return
+		}
+		v.lastSummary = summary
+		fmt.Fprintf(v.writer, "[%s] %s\n", v.elapsed(progress), summary)
+		return
+	}
+
+	v.erase()
+	lines := v.frameLines(progress)
+	for _, line := range lines {
+		fmt.Fprintln(v.writer, truncate(line, v.width-1))
+	}
+	v.frame = len(lines)
+}
+
+// Clear removes the progress drawn on the terminal. It must be called before the command prints its result.
+func (v *ProgressView) Clear() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.erase()
+	v.lastSummary = ""
+}
+
+// Write writes the text above the progress drawn on the terminal.
+func (v *ProgressView) Write(p []byte) (int, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.frame == 0 {
+		return v.writer.Write(p)
+	}
+
+	// The progress is drawn again on the next update
+	v.erase()
+	return v.writer.Write(p)
+}
+
+// erase moves the cursor to the first line of the drawn progress and clears the screen below it.
+func (v *ProgressView) erase() {
+	if v.frame > 0 {
+		fmt.Fprintf(v.writer, "\x1b[%dA\x1b[J", v.frame)
+		v.frame = 0
+	}
+}
+
+func (v *ProgressView) elapsed(progress *models.WaitProgress) string {
+	elapsed := v.now().Sub(progress.Started).Round(time.Second).String()
+	if progress.Deadline.IsZero() {
+		return elapsed
+	}
+	return fmt.Sprintf("%s/%s", elapsed, progress.Deadline.Sub(progress.Started).Round(time.Second))
+}
+
+func (v *ProgressView) frameLines(progress *models.WaitProgress) []string {
+	lines := []string{
+		fmt.Sprintf("%s %s: phase %s, waiting for %s (elapsed %s)", resourceName("", progress.Name), progress.Operation, valueOrNone(progress.Phase), progress.TargetPhase, v.elapsed(progress)),
+	}
+	if progress.Job == nil {
+		lines = append(lines, "Job: <none>")
+		return lines
+	}
+	lines = append(lines, fmt.Sprintf("Job: %s %s", progress.Job.Name, jobSummary(progress.Job)))
+	for _, pod := range progress.Job.Pods {
+		lines = append(lines, fmt.Sprintf("  pod/%s %s, %d restarts", pod.Name, pod.Phase, pod.Restarts))
+	}
+	for _, line := range progress.LogLines {
+		lines = append(lines, "  "+line)
+	}
+	return lines
+}
+
+// progressSummary describes the progress in a single line without the elapsed time,
+// so it only changes when the state of the stream or its job changes.
+func progressSummary(progress *models.WaitProgress) string {
+	summary := fmt.Sprintf("%s %s: phase %s, waiting for %s", resourceName("", progress.Name), progress.Operation, valueOrNone(progress.Phase), progress.TargetPhase)
+	if progress.Job == nil {
+		return summary + ", job <none>"
+	}
+	return fmt.Sprintf("%s, job %s", summary, jobSummary(progress.Job))
+}
+
+func jobSummary(job *models.JobStatus) string {
+	return fmt.Sprintf("%d active / %d succeeded / %d failed", job.Active, job.Succeeded, job.Failed)
+}
+
+// truncate cuts the line to the width so it is not wrapped by the terminal.
+func truncate(line string, width int) string {
+	runes := []rune(strings.ReplaceAll(line, "\t", " "))
+	if width <= 0 || len(runes) <= width {
+		return string(runes)
+	}
+	return string(runes[:width])
+}
@@ -0,0 +1,379 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"s-vitaliy/kubectl-plugin-arcane/internal/bundle"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+
+	"sigs.k8s.io/yaml"
+)
+
+// writeText renders the result in the human-readable form.
+// Results of unknown types are rendered as yaml.
+func writeText(writer io.Writer, result any, wide bool) error {
+	switch value := result.(type) {
+	case *models.StreamList:
+		return writeStreamList(writer, value, wide)
+	case *models.StreamStatus:
+		return writeStreamStatus(writer, value)
+	case *models.StreamDescription:
+		return writeStreamDescription(writer, value)
+	case *models.OperationResult:
+		return writeOperationResult(writer, value, wide)
+	case *models.BulkOperationResult:
+		return writeBulkOperationResult(writer, value)
+	case *models.BundleResult:
+		return writeBundleResult(writer, value)
+	case *models.StreamBundle:
+		return bundle.Write(writer, value)
+	case *models.StreamDiff:
+		return writeStreamDiff(writer, value)
+	case *models.StreamTimeline:
+		return writeStreamTimeline(writer, value, wide)
+	default:
+		return (&yamlPrinter{writer: writer}).Print(result)
+	}
+}
+
+// resourceNames returns the names of the resources in the result in the resource/name form.
+func resourceNames(result any) []string {
+	switch value := result.(type) {
+	case *models.StreamList:
+		names := make([]string, 0, len(value.Items))
+		for _, stream := range value.Items {
+			names = append(names, resourceName(stream.Kind, stream.Name))
+		}
+		return names
+	case *models.StreamStatus:
+		return []string{resourceName(value.Kind, value.Name)}
+	case *models.StreamDescription:
+		return []string{resourceName(value.Kind, value.Name)}
+	case *models.StreamTimeline:
+		return []string{resourceName("", value.Name)}
+	case *models.OperationResult:
+		return []string{resourceName("", value.Name)}
+	case *models.StreamDiff:
+		if !value.IsDifferent() {
+			return nil
+		}
+		return []string{resourceName("", value.Name)}
+	case *models.BulkOperationResult:
+		names := make([]string, 0, len(value.Items))
+		for _, item := range value.Items {
+			if item.Error == "" {
+				names = append(names, resourceName("", item.Name))
+			}
+		}
+		return names
+	case *models.StreamBundle:
+		names := make([]string, 0, len(value.Objects))
+		for _, object := range value.Objects {
+			names = append(names, resourceName(object.GetKind(), object.GetName()))
+		}
+		return names
+	case *models.BundleResult:
+		names := make([]string, 0, len(value.Items))
+		for _, item := range value.Items {
+			if item.Error == "" {
+				names = append(names, resourceName(item.Kind, item.Name))
+			}
+		}
+		return names
+	default:
+		return nil
+	}
+}
+
+func resourceName(kind string, name string) string {
+	if kind == "" {
+		kind = "stream"
+	}
+	return strings.ToLower(kind) + "/" + name
+}
+
+func newTabWriter(writer io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(writer, 0, 0, 3, ' ', 0)
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
+
+func writeStreamList(writer io.Writer, list *models.StreamList, wide bool) error {
+	if len(list.Items) == 0 {
+		_, err := fmt.Fprintln(writer, "No streams found.")
+		return err
+	}
+	table := newTabWriter(writer)
+	if wide {
+		fmt.Fprintln(table, "NAMESPACE\tNAME\tSTREAM CLASS\tKIND\tPHASE\tSTATE")
+	} else {
+		fmt.Fprintln(table, "NAME\tSTREAM CLASS\tKIND\tPHASE\tSTATE")
+	}
+	for _, stream := range list.Items {
+		if wide {
+			fmt.Fprintf(table, "%s\t", stream.Namespace)
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", stream.Name, stream.StreamClass, stream.Kind, valueOrNone(stream.Phase), valueOrNone(stream.State))
+	}
+	return table.Flush()
+}
+
+func writeStreamStatus(writer io.Writer, status *models.StreamStatus) error {
+	table := newTabWriter(writer)
+	fmt.Fprintf(table, "Name:\t%s\n", status.Name)
+	fmt.Fprintf(table, "Namespace:\t%s\n", status.Namespace)
+	fmt.Fprintf(table, "Kind:\t%s\n", status.Kind)
+	fmt.Fprintf(table, "Stream Class:\t%s\n", valueOrNone(status.StreamClass))
+	fmt.Fprintf(table, "Phase:\t%s\n", valueOrNone(status.Phase))
+	fmt.Fprintf(table, "State:\t%s\n", valueOrNone(status.State))
+	if status.Message != "" {
+		fmt.Fprintf(table, "Message:\t%s\n", status.Message)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(writer, "Conditions:")
+	if len(status.Conditions) == 0 {
+		fmt.Fprintln(writer, "  <none>")
+	} else {
+		table = newTabWriter(writer)
+		fmt.Fprintln(table, "  TYPE\tSTATUS\tREASON\tLAST TRANSITION\tMESSAGE")
+		for _, condition := range status.Conditions {
+			fmt.Fprintf(table, "  %s\t%s\t%s\t%s\t%s\n", condition.Type, condition.Status, valueOrNone(condition.Reason), valueOrNone(condition.LastTransitionTime), condition.Message)
+		}
+		if err := table.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if status.Job == nil {
+		_, err := fmt.Fprintln(writer, "Job:   <none>")
+		return err
+	}
+	table = newTabWriter(writer)
+	fmt.Fprintln(table, "Job:")
+	fmt.Fprintf(table, "  Name:\t%s\n", status.Job.Name)
+	fmt.Fprintf(table, "  Start Time:\t%s\n", valueOrNone(status.Job.StartTime))
+	fmt.Fprintf(table, "  Pods Statuses:\t%d Active / %d Succeeded / %d Failed\n", status.Job.Active, status.Job.Succeeded, status.Job.Failed)
+	if status.Job.FailureReason != "" {
+		fmt.Fprintf(table, "  Failure Reason:\t%s\n", status.Job.FailureReason)
+	}
+	fmt.Fprintln(table, "  Pods:")
+	if len(status.Job.Pods) == 0 {
+		fmt.Fprintln(table, "    <none>")
+		return table.Flush()
+	}
+	fmt.Fprintln(table, "    NAME\tPHASE\tRESTARTS\tSTART TIME")
+	for _, pod := range status.Job.Pods {
+		fmt.Fprintf(table, "    %s\t%s\t%d\t%s\n", pod.Name, pod.Phase, pod.Restarts, valueOrNone(pod.StartTime))
+	}
+	return table.Flush()
+}
+
+func writeStreamDescription(writer io.Writer, description *models.StreamDescription) error {
+	table := newTabWriter(writer)
+	fmt.Fprintf(table, "Name:\t%s\n", description.Name)
+	fmt.Fprintf(table, "Namespace:\t%s\n", description.Namespace)
+	fmt.Fprintf(table, "Kind:\t%s\n", description.Kind)
+	fmt.Fprintf(table, "Stream Class:\t%s\n", valueOrNone(description.StreamClass))
+	fmt.Fprintf(table, "Phase:\t%s\n", valueOrNone(description.Phase))
+	fmt.Fprintf(table, "State:\t%s\n", valueOrNone(description.State))
+	fmt.Fprintf(table, "Labels:\t%s\n", valueOrNone(joinMap(description.Labels)))
+	fmt.Fprintf(table, "Annotations:\t%s\n", valueOrNone(joinMap(description.Annotations)))
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(writer, "Spec:")
+	if len(description.Spec) == 0 {
+		fmt.Fprintln(writer, "  <none>")
+	} else {
+		spec, err := yaml.Marshal(description.Spec)
+		if err != nil {
+			return fmt.Errorf("failed to marshal stream spec to yaml: %w", err)
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(string(spec), "\n"), "\n") {
+			fmt.Fprintf(writer, "  %s\n", line)
+		}
+	}
+
+	fmt.Fprintln(writer, "References:")
+	table = newTabWriter(writer)
+	fmt.Fprintln(table, "  FIELD\tOBJECT\tSTATUS")
+	for _, reference := range description.References {
+		object := reference.Kind + "/" + reference.Name
+		if reference.Key != "" {
+			object = fmt.Sprintf("%s (key: %s)", object, reference.Key)
+		}
+		status := "OK"
+		if reference.IsBroken() {
+			status = "BROKEN: " + reference.Problem
+		} else if reference.Kind == models.ReferenceKindSecret {
+			// The secret values are never shown, only the keys
+			status = fmt.Sprintf("OK (keys: %s)", valueOrNone(strings.Join(reference.Keys, ", ")))
+		}
+		fmt.Fprintf(table, "  %s\t%s\t%s\n", valueOrNone(reference.Field), object, status)
+	}
+	return table.Flush()
+}
+
+// joinMap renders the labels or annotations in the key=value form sorted by the keys.
+func joinMap(values map[string]string) string {
+	pairs := make([]string, 0, len(values))
+	for key, value := range values {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
+
+func writeOperationResult(writer io.Writer, result *models.OperationResult, wide bool) error {
+	line := fmt.Sprintf("%s %s", resourceName("", result.Name), result.Operation)
+	if result.Phase != "" {
+		line = fmt.Sprintf("%s (phase: %s)", line, result.Phase)
+	}
+	if result.Message != "" {
+		line = fmt.Sprintf("%s (%s)", line, result.Message)
+	}
+	if wide {
+		line = fmt.Sprintf("%s in namespace %s", line, result.Namespace)
+	}
+	if len(result.Patches) > 0 {
+		line = fmt.Sprintf("%s (dry run: %s)", line, result.Patches[0].DryRun)
+	} else if result.DryRun.IsDryRun() {
+		line = fmt.Sprintf("%s (dry run: %s)", line, result.DryRun)
+	}
+	if _, err := fmt.Fprintln(writer, line); err != nil {
+		return err
+	}
+	if result.Manifest != "" {
+		if _, err := fmt.Fprint(writer, result.Manifest); err != nil {
+			return err
+		}
+	}
+	if result.Diff != "" {
+		if _, err := fmt.Fprint(writer, result.Diff); err != nil {
+			return err
+		}
+	}
+	for _, patch := range result.Patches {
+		if err := writePatchResult(writer, patch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writePatchResult(writer io.Writer, patch *models.PatchResult) error {
+	fmt.Fprintf(writer, "PATCH %s/%s (application/merge-patch+json)\n", patch.Resource, patch.Name)
+	fmt.Fprintf(writer, "%s\n", patch.Patch)
+	if patch.DryRun == models.DryRunServer {
+		if patch.Diff == "" {
+			_, err := fmt.Fprintln(writer, "No changes.")
+			return err
+		}
+		_, err := fmt.Fprint(writer, patch.Diff)
+		return err
+	}
+	return nil
+}
+
+func writeBulkOperationResult(writer io.Writer, result *models.BulkOperationResult) error {
+	table := newTabWriter(writer)
+	fmt.Fprintln(table, "NAME\tSTREAM CLASS\tRESULT")
+	for _, item := range result.Items {
+		outcome := fmt.Sprintf("failed: %s", item.Error)
+		if item.Result != nil {
+			outcome = item.Result.Operation
+			if item.Result.Phase != "" {
+				outcome = fmt.Sprintf("%s (phase: %s)", outcome, item.Result.Phase)
+			}
+			if item.Result.Message != "" {
+				outcome = fmt.Sprintf("%s (%s)", outcome, item.Result.Message)
+			}
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\n", item.Name, item.StreamClass, outcome)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(writer, "%d succeeded, %d failed\n", len(result.Items)-result.Failed(), result.Failed())
+	return err
+}
+
+// writeStreamDiff writes the unified diff, nothing if the stream does not differ from the manifest.
+func writeStreamDiff(writer io.Writer, result *models.StreamDiff) error {
+	text := result.Diff
+	if result.Color {
+		text = colorDiff(text)
+	}
+	_, err := fmt.Fprint(writer, text)
+	return err
+}
+
+func writeBundleResult(writer io.Writer, result *models.BundleResult) error {
+	table := newTabWriter(writer)
+	fmt.Fprintln(table, "KIND\tNAME\tRESULT")
+	var operations []string
+	counts := map[string]int{}
+	for _, item := range result.Items {
+		outcome := fmt.Sprintf("failed: %s", item.Error)
+		if item.Error == "" {
+			outcome = item.Operation
+			if item.Message != "" {
+				outcome = fmt.Sprintf("%s (%s)", outcome, item.Message)
+			}
+			if counts[item.Operation] == 0 {
+				operations = append(operations, item.Operation)
+			}
+			counts[item.Operation]++
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\n", item.Kind, item.Name, outcome)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	summary := make([]string, 0, len(operations)+1)
+	for _, operation := range operations {
+		summary = append(summary, fmt.Sprintf("%d %s", counts[operation], operation))
+	}
+	summary = append(summary, fmt.Sprintf("%d failed", result.Failed()))
+	line := strings.Join(summary, ", ")
+	if result.DryRun.IsDryRun() {
+		line = fmt.Sprintf("%s (dry run: %s)", line, result.DryRun)
+	}
+	_, err := fmt.Fprintln(writer, line)
+	return err
+}
+
+func writeStreamTimeline(writer io.Writer, timeline *models.StreamTimeline, wide bool) error {
+	if len(timeline.Entries) == 0 {
+		_, err := fmt.Fprintf(writer, "No events found for stream %s.\n", timeline.Name)
+		return err
+	}
+	table := newTabWriter(writer)
+	if wide {
+		fmt.Fprintln(table, "TIME\tTYPE\tOBJECT\tREASON\tCOUNT\tMESSAGE")
+	} else {
+		fmt.Fprintln(table, "TIME\tTYPE\tOBJECT\tREASON\tMESSAGE")
+	}
+	for _, entry := range timeline.Entries {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t", entry.Time.Format(time.RFC3339), entry.Type, entry.Object, entry.Reason)
+		if wide {
+			fmt.Fprintf(table, "%d\t", max(entry.Count, 1))
+		}
+		fmt.Fprintf(table, "%s\n", entry.Message)
+	}
+	return table.Flush()
+}
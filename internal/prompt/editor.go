@@ -0,0 +1,48 @@
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Edit opens the text in the editor of the user and returns the edited text. Like kubectl edit, the editor is
+// taken from the KUBE_EDITOR or EDITOR environment variables and defaults to vi.
+func Edit(name string, text string) (string, error) {
+	file, err := os.CreateTemp("", name+"-*.yaml")
+	if err != nil {
+		return "", fmt.Errorf("failed to create the file to edit: %w", err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.WriteString(text); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to write the file to edit: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write the file to edit: %w", err)
+	}
+
+	editor := editorCommand()
+	command := exec.Command(editor[0], append(editor[1:], file.Name())...)
+	command.Stdin, command.Stdout, command.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := command.Run(); err != nil {
+		return "", fmt.Errorf("editor %s failed: %w", editor[0], err)
+	}
+
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read the edited file: %w", err)
+	}
+	return string(edited), nil
+}
+
+// editorCommand returns the editor command with its arguments.
+func editorCommand() []string {
+	for _, variable := range []string{"KUBE_EDITOR", "EDITOR"} {
+		if command := strings.Fields(os.Getenv(variable)); len(command) > 0 {
+			return command
+		}
+	}
+	return []string{"vi"}
+}
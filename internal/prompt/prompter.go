@@ -0,0 +1,50 @@
+// Package prompt asks the user for the values and confirmations the commands need.
+package prompt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Prompter writes the questions to the writer and reads the answers line by line from the reader.
+type Prompter struct {
+	reader      *bufio.Reader
+	writer      io.Writer
+	interactive bool
+}
+
+// NewPrompter creates a prompter. The interactive flag tells whether a user is attached to the reader,
+// the commands must not wait for the answers otherwise.
+func NewPrompter(reader io.Reader, writer io.Writer, interactive bool) *Prompter {
+	return &Prompter{reader: bufio.NewReader(reader), writer: writer, interactive: interactive}
+}
+
+// IsInteractive checks whether a user can answer the questions.
+func (p *Prompter) IsInteractive() bool {
+	return p.interactive
+}
+
+// Ask writes the question and returns the answer without the surrounding spaces.
+// It returns io.EOF if the input ends before the answer.
+func (p *Prompter) Ask(question string) (string, error) {
+	if _, err := fmt.Fprint(p.writer, question); err != nil {
+		return "", err
+	}
+	line, err := p.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// Confirm asks the yes or no question, only the y and yes answers confirm.
+func (p *Prompter) Confirm(question string) (bool, error) {
+	answer, err := p.Ask(question + " [y/N]: ")
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToLower(answer)
+	return answer == "y" || answer == "yes", nil
+}
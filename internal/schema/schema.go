@@ -0,0 +1,292 @@
+// Package schema scaffolds and validates the stream resources with the OpenAPI v3 schema of the stream CRD.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+	"sigs.k8s.io/yaml"
+)
+
+// Skeleton returns a value matching the schema with the required properties only.
+// The default values of the schema are kept, the other leaves get the zero values of their types.
+func Skeleton(schema map[string]any) any {
+	if value, ok := schema["default"]; ok {
+		return runtime.DeepCopyJSONValue(value)
+	}
+	switch schemaType(schema) {
+	case "object":
+		object := map[string]any{}
+		schemaProperties := properties(schema)
+		for _, name := range required(schema) {
+			if property, ok := schemaProperties[name]; ok {
+				object[name] = Skeleton(property)
+			}
+		}
+		return object
+	case "array":
+		return []any{}
+	case "integer":
+		return int64(0)
+	case "number":
+		return float64(0)
+	case "boolean":
+		return false
+	default:
+		return ""
+	}
+}
+
+// RequiredFields returns the required leaf fields of the schema without default values, the values a user must provide.
+// The paths of the fields start with the prefix.
+func RequiredFields(schema map[string]any, prefix string) []models.SchemaField {
+	var fields []models.SchemaField
+	schemaProperties := properties(schema)
+	for _, name := range required(schema) {
+		property, ok := schemaProperties[name]
+		if !ok {
+			continue
+		}
+		if _, ok := property["default"]; ok {
+			continue
+		}
+		path := joinPath(prefix, name)
+		if schemaType(property) == "object" && len(properties(property)) > 0 {
+			fields = append(fields, RequiredFields(property, path)...)
+			continue
+		}
+		field := models.SchemaField{Path: path, Type: schemaType(property)}
+		field.Description, _ = property["description"].(string)
+		field.Enum, _ = property["enum"].([]any)
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// Lookup returns the schema of the field at the dot-separated path, e.g. spec.sourceSettings.schema.
+// The array items are addressed by their indexes.
+func Lookup(schema map[string]any, path string) (map[string]any, error) {
+	current := schema
+	for _, name := range strings.Split(path, ".") {
+		if preservesUnknownFields(current) && len(properties(current)) == 0 {
+			return map[string]any{}, nil
+		}
+		switch schemaType(current) {
+		case "array":
+			if _, err := strconv.Atoi(name); err != nil {
+				return nil, fmt.Errorf("field %s: %s is not an array index", path, name)
+			}
+			items, ok := current["items"].(map[string]any)
+			if !ok {
+				return map[string]any{}, nil
+			}
+			current = items
+		case "object", "":
+			if property, ok := properties(current)[name]; ok {
+				current = property
+				continue
+			}
+			if additional, ok := current["additionalProperties"].(map[string]any); ok {
+				current = additional
+				continue
+			}
+			return nil, fmt.Errorf("field %s: unknown field %s", path, name)
+		default:
+			return nil, fmt.Errorf("field %s: %s is not an object", path, name)
+		}
+	}
+	return current, nil
+}
+
+// SetValue sets the field at the dot-separated path of the object to the value and creates the missing parents.
+// The array items are addressed by their indexes, the index equal to the array length appends the item.
+func SetValue(object map[string]any, path string, value any) error {
+	_, err := setValue(object, strings.Split(path, "."), path, value)
+	return err
+}
+
+func setValue(current any, names []string, path string, value any) (any, error) {
+	if len(names) == 0 {
+		return value, nil
+	}
+	name := names[0]
+	switch container := current.(type) {
+	case map[string]any:
+		child, err := setValue(container[name], names[1:], path, value)
+		if err != nil {
+			return nil, err
+		}
+		container[name] = child
+		return container, nil
+	case []any:
+		index, err := strconv.Atoi(name)
+		if err != nil || index < 0 || index > len(container) {
+			return nil, fmt.Errorf("field %s: %s is not an index of an array of %d items", path, name, len(container))
+		}
+		if index == len(container) {
+			container = append(container, nil)
+		}
+		child, err := setValue(container[index], names[1:], path, value)
+		if err != nil {
+			return nil, err
+		}
+		container[index] = child
+		return container, nil
+	case nil:
+		if _, err := strconv.Atoi(name); err == nil {
+			return setValue([]any{}, names, path, value)
+		}
+		return setValue(map[string]any{}, names, path, value)
+	default:
+		return nil, fmt.Errorf("field %s: the parent of %s is not an object", path, name)
+	}
+}
+
+// ParseValue converts the text to the type of the schema. The objects, arrays and values of unknown types
+// are parsed as yaml, e.g. [a, b] or {key: value}.
+func ParseValue(schema map[string]any, text string) (any, error) {
+	switch schemaType(schema) {
+	case "string":
+		return text, nil
+	case "integer":
+		value, err := strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", text)
+		}
+		return value, nil
+	case "number":
+		value, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", text)
+		}
+		return value, nil
+	case "boolean":
+		value, err := strconv.ParseBool(strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", text)
+		}
+		return value, nil
+	default:
+		return ParseYaml([]byte(text))
+	}
+}
+
+// ParseYaml parses the yaml or json document into the values used by the unstructured objects.
+func ParseYaml(data []byte) (any, error) {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse yaml: %w", err)
+	}
+	var value any
+	if err := utiljson.Unmarshal(jsonData, &value); err != nil {
+		return nil, fmt.Errorf("failed to parse yaml: %w", err)
+	}
+	return value, nil
+}
+
+// Validate checks the object against the schema and returns the validation errors.
+func Validate(schema map[string]any, object map[string]any) ([]string, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+	var openApiSchema spec.Schema
+	if err := json.Unmarshal(data, &openApiSchema); err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	result := validate.NewSchemaValidator(&openApiSchema, nil, "", strfmt.Default).Validate(object)
+	messages := make([]string, 0, len(result.Errors))
+	for _, err := range result.Errors {
+		messages = append(messages, err.Error())
+	}
+	sort.Strings(messages)
+	return slices.Compact(messages), nil
+}
+
+// ApplyDefaults sets the missing properties of the value to the default values of the schema, like the API server
+// does for the custom resources. The defaults are applied to the nested objects and array items as well.
+func ApplyDefaults(schema map[string]any, value any) {
+	switch typed := value.(type) {
+	case map[string]any:
+		schemaProperties := properties(schema)
+		for name, property := range schemaProperties {
+			if _, ok := typed[name]; ok {
+				continue
+			}
+			if defaultValue, ok := property["default"]; ok {
+				typed[name] = runtime.DeepCopyJSONValue(defaultValue)
+			}
+		}
+		additional, _ := schema["additionalProperties"].(map[string]any)
+		for name, field := range typed {
+			if property, ok := schemaProperties[name]; ok {
+				ApplyDefaults(property, field)
+			} else if additional != nil {
+				ApplyDefaults(additional, field)
+			}
+		}
+	case []any:
+		items, ok := schema["items"].(map[string]any)
+		if !ok {
+			return
+		}
+		for _, item := range typed {
+			ApplyDefaults(items, item)
+		}
+	}
+}
+
+func schemaType(schema map[string]any) string {
+	value, _ := schema["type"].(string)
+	if value == "" && len(properties(schema)) > 0 {
+		return "object"
+	}
+	return value
+}
+
+func properties(schema map[string]any) map[string]map[string]any {
+	values, _ := schema["properties"].(map[string]any)
+	properties := make(map[string]map[string]any, len(values))
+	for name, value := range values {
+		if property, ok := value.(map[string]any); ok {
+			properties[name] = property
+		}
+	}
+	return properties
+}
+
+// required returns the names of the required properties in the order of the schema.
+func required(schema map[string]any) []string {
+	values, _ := schema["required"].([]any)
+	names := make([]string, 0, len(values))
+	for _, value := range values {
+		if name, ok := value.(string); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func preservesUnknownFields(schema map[string]any) bool {
+	preserve, _ := schema["x-kubernetes-preserve-unknown-fields"].(bool)
+	return preserve
+}
+
+func joinPath(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
@@ -0,0 +1,59 @@
+package test_app
+
+import (
+	"fmt"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunBulkOperationContinuesAfterFailures(t *testing.T) {
+	streams := []*models.StreamSummary{
+		{Name: "first", StreamClass: "class"},
+		{Name: "broken", StreamClass: "class"},
+		{Name: "third", StreamClass: "class"},
+	}
+
+	result := app.RunBulkOperation(streams, 2, func(stream *models.StreamSummary) (*models.OperationResult, error) {
+		if stream.Name == "broken" {
+			return nil, fmt.Errorf("stream %s is broken", stream.Name)
+		}
+		return models.NewOperationResult(stream.Name, "arcane", "suspended"), nil
+	})
+
+	assert.Len(t, result.Items, 3)
+	assert.Equal(t, "first", result.Items[0].Name)
+	assert.Equal(t, "suspended", result.Items[0].Result.Operation)
+	assert.Equal(t, "stream broken is broken", result.Items[1].Error)
+	assert.Equal(t, "third", result.Items[2].Name)
+	assert.Equal(t, 1, result.Failed())
+	assert.Error(t, result.Err())
+}
+
+func TestRunBulkOperationLimitsParallelism(t *testing.T) {
+	streams := make([]*models.StreamSummary, 10)
+	for i := range streams {
+		streams[i] = &models.StreamSummary{Name: fmt.Sprintf("stream-%d", i)}
+	}
+
+	var running, maxRunning atomic.Int32
+	result := app.RunBulkOperation(streams, 3, func(stream *models.StreamSummary) (*models.OperationResult, error) {
+		current := running.Add(1)
+		defer running.Add(-1)
+		for {
+			observed := maxRunning.Load()
+			if current <= observed || maxRunning.CompareAndSwap(observed, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return models.NewOperationResult(stream.Name, "arcane", "resumed"), nil
+	})
+
+	assert.NoError(t, result.Err())
+	assert.LessOrEqual(t, maxRunning.Load(), int32(3))
+}
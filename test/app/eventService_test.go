@@ -0,0 +1,51 @@
+package test_app
+
+import (
+	"io"
+	"log/slog"
+	"s-vitaliy/kubectl-plugin-arcane/internal/client/api/common"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newEvent(name string, kind string, objectName string, reason string, at time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "arcane"},
+		InvolvedObject: corev1.ObjectReference{Kind: kind, Name: objectName, Namespace: "arcane"},
+		Type:           corev1.EventTypeNormal,
+		Reason:         reason,
+		LastTimestamp:  metav1.NewTime(at),
+	}
+}
+
+func TestListStreamEvents(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	client := fake.NewClientset(
+		newEvent("stream", "MicrosoftSqlServerStream", "mock-mssql-stream", "Reloading", now),
+		newEvent("job", "Job", "mock-mssql-stream", "SuccessfulCreate", now.Add(time.Second)),
+		newEvent("pod", "Pod", "mock-mssql-stream-abcde", "Started", now.Add(2*time.Second)),
+		newEvent("other-pod", "Pod", "mock-mssql-stream-other-abcde", "Started", now),
+		newEvent("other-job", "Job", "mock-mssql-stream-other", "SuccessfulCreate", now),
+		newEvent("config-map", "ConfigMap", "mock-mssql-stream", "Updated", now),
+		newEvent("service", "Service", "mock-mssql-stream", "Created", now),
+	)
+	service := common.ProvideEventService(slog.New(slog.NewTextHandler(io.Discard, nil)), client)
+
+	entries, err := service.ListStreamEvents(t.Context(), "mock-mssql-stream", "arcane", "MicrosoftSqlServerStream")
+
+	assert.NoError(t, err)
+	objects := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		objects = append(objects, entry.Object)
+	}
+	assert.ElementsMatch(t, []string{
+		"microsoftsqlserverstream/mock-mssql-stream",
+		"job/mock-mssql-stream",
+		"pod/mock-mssql-stream-abcde",
+	}, objects)
+}
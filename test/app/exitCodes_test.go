@@ -0,0 +1,44 @@
+package test_app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestExitCode(t *testing.T) {
+	streams := schema.GroupResource{Group: "streaming.sneaksanddata.com", Resource: "microsoft-sql-server-streams"}
+	cases := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"success", nil, app.ExitCodeSuccess},
+		{"unclassified", errors.New("something went wrong"), app.ExitCodeError},
+		{"stream not found", fmt.Errorf("failed to discover: %w", &abstractions.StreamNotFoundError{Id: "mock-mssql-stream", Namespace: "arcane"}), app.ExitCodeStreamNotFound},
+		{"stream class not found", &abstractions.StreamClassNotFoundError{StreamClass: "arcane-stream-mock", Namespace: "arcane"}, app.ExitCodeStreamClassNotFound},
+		{"annotation missing", fmt.Errorf("%w: job mock-mssql-stream", abstractions.ErrAnnotationMissing), app.ExitCodeAnnotationMissing},
+		{"invalid transition", &app.InvalidTransitionError{Operation: app.OperationBackfill, Id: "mock-mssql-stream", Reason: "the stream has failed"}, app.ExitCodeInvalidTransition},
+		{"timeout", fmt.Errorf("failed to wait: %w", &abstractions.WaitTimeoutError{Id: "mock-mssql-stream", Phase: "Running", Err: context.DeadlineExceeded}), app.ExitCodeTimeout},
+		{"deletion timeout", &abstractions.WaitTimeoutError{Id: "mock-mssql-stream", Condition: "to be deleted", Err: context.DeadlineExceeded}, app.ExitCodeTimeout},
+		{"stream failed", &abstractions.StreamFailedError{Id: "mock-mssql-stream", Phase: "Failed"}, app.ExitCodeStreamFailed},
+		{"invalid manifest", &abstractions.InvalidManifestError{Name: "mock-mssql-stream", Errors: []string{"spec.sourceSettings.fetchSize in body must be of type integer"}}, app.ExitCodeInvalidManifest},
+		{"stream differs", &abstractions.StreamDiffersError{Name: "mock-mssql-stream", Namespace: "arcane"}, app.ExitCodeStreamDiffers},
+		{"forbidden", fmt.Errorf("failed to patch: %w", apierrors.NewForbidden(streams, "mock-mssql-stream", errors.New("denied"))), app.ExitCodeApiError},
+		{"unauthorized", apierrors.NewUnauthorized("token expired"), app.ExitCodeApiError},
+		{"unreachable", &url.Error{Op: "Get", URL: "https://127.0.0.1:6443", Err: errors.New("connection refused")}, app.ExitCodeApiError},
+	}
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, app.ExitCode(testCase.err))
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package test_app
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"s-vitaliy/kubectl-plugin-arcane/internal/client/api/common"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newJobPod(name string, containers ...string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "arcane",
+			Labels:    map[string]string{"job-name": "mock-mssql-stream"},
+		},
+	}
+	for _, container := range containers {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: container})
+	}
+	return pod
+}
+
+func TestReadLogsPrefixesPodNames(t *testing.T) {
+	client := fake.NewClientset(newJobPod("mock-mssql-stream-abcde", "arcane-stream", "sidecar"))
+	service := common.ProvideJobLogService(slog.New(slog.NewTextHandler(io.Discard, nil)), client)
+
+	var buffer bytes.Buffer
+	err := service.ReadLogs(t.Context(), "mock-mssql-stream", "arcane", models.LogOptions{}, &buffer)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "[pod/mock-mssql-stream-abcde] fake logs\n", buffer.String())
+}
+
+func TestReadLogsOfAllContainers(t *testing.T) {
+	client := fake.NewClientset(newJobPod("mock-mssql-stream-abcde", "arcane-stream", "sidecar"))
+	service := common.ProvideJobLogService(slog.New(slog.NewTextHandler(io.Discard, nil)), client)
+
+	var buffer bytes.Buffer
+	err := service.ReadLogs(t.Context(), "mock-mssql-stream", "arcane", models.LogOptions{AllContainers: true}, &buffer)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "[pod/mock-mssql-stream-abcde/arcane-stream] fake logs\n[pod/mock-mssql-stream-abcde/sidecar] fake logs\n", buffer.String())
+}
+
+func TestReadLogsWithoutPods(t *testing.T) {
+	client := fake.NewClientset()
+	service := common.ProvideJobLogService(slog.New(slog.NewTextHandler(io.Discard, nil)), client)
+
+	err := service.ReadLogs(t.Context(), "mock-mssql-stream", "arcane", models.LogOptions{}, io.Discard)
+
+	assert.Error(t, err)
+}
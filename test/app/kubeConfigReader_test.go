@@ -0,0 +1,75 @@
+package test_app
+
+import (
+	"os"
+	"path/filepath"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: https://127.0.0.1:6443
+users:
+- name: test
+  user:
+    token: test
+contexts:
+- name: no-namespace
+  context:
+    cluster: test
+    user: test
+- name: with-namespace
+  context:
+    cluster: test
+    user: test
+    namespace: streams
+current-context: no-namespace
+`
+
+func writeKubeConfig(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "config")
+	err := os.WriteFile(path, []byte(testKubeConfig), 0600)
+	assert.NoError(t, err)
+	return path
+}
+
+func TestReadNamespace(t *testing.T) {
+	path := writeKubeConfig(t)
+	cases := []struct {
+		name     string
+		options  models.ConnectionOptions
+		expected string
+	}{
+		{"default namespace", models.ConnectionOptions{Kubeconfig: path}, app.DefaultNamespace},
+		{"context namespace", models.ConnectionOptions{Kubeconfig: path, Context: "with-namespace"}, "streams"},
+		{"namespace flag", models.ConnectionOptions{Kubeconfig: path, Context: "with-namespace", Namespace: "other"}, "other"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			reader, err := app.ProvideConfigReader(&c.options)
+			assert.NoError(t, err)
+
+			namespace, err := reader.ReadNamespace()
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, namespace)
+		})
+	}
+}
+
+func TestReadConfigUsesContext(t *testing.T) {
+	path := writeKubeConfig(t)
+	reader, err := app.ProvideConfigReader(&models.ConnectionOptions{Kubeconfig: path, Context: "with-namespace"})
+	assert.NoError(t, err)
+
+	config, err := reader.ReadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://127.0.0.1:6443", config.Host)
+}
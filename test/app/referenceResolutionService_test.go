@@ -0,0 +1,56 @@
+package test_app
+
+import (
+	"io"
+	"log/slog"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/client/api/common"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"s-vitaliy/kubectl-plugin-arcane/test/fakes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newReferenceResolver(t *testing.T, objects ...*unstructured.Unstructured) abstractions.ReferenceResolver {
+	client := fakes.NewDynamicClient(t, objects...)
+	return common.ProvideReferenceResolutionService(slog.New(slog.NewTextHandler(io.Discard, nil)), client)
+}
+
+func TestResolveSecretReadsKeysOnly(t *testing.T) {
+	resolver := newReferenceResolver(t, fakes.NewSecret("db-credentials", map[string]string{
+		"password": "s3cr3t",
+		"url":      "sqlserver://db",
+	}))
+	reference := &models.StreamReference{Kind: models.ReferenceKindSecret, Name: "db-credentials", Key: "url"}
+
+	err := resolver.Resolve(t.Context(), reference, fakes.Namespace)
+
+	assert.NoError(t, err)
+	assert.False(t, reference.IsBroken())
+	assert.Equal(t, []string{"password", "url"}, reference.Keys)
+	assert.NotContains(t, reference.Keys, "s3cr3t")
+}
+
+func TestResolveSecretFlagsMissingKey(t *testing.T) {
+	resolver := newReferenceResolver(t, fakes.NewSecret("db-credentials", map[string]string{"password": "s3cr3t"}))
+	reference := &models.StreamReference{Kind: models.ReferenceKindSecret, Name: "db-credentials", Key: "url"}
+
+	err := resolver.Resolve(t.Context(), reference, fakes.Namespace)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "key url not found in Secret db-credentials", reference.Problem)
+}
+
+func TestResolveFlagsMissingJobTemplate(t *testing.T) {
+	resolver := newReferenceResolver(t, fakes.NewJobTemplate("standard-job"))
+	found := &models.StreamReference{Kind: models.ReferenceKindJobTemplate, Name: "standard-job"}
+	missing := &models.StreamReference{Kind: models.ReferenceKindJobTemplate, Name: "large-job"}
+
+	assert.NoError(t, resolver.Resolve(t.Context(), found, fakes.Namespace))
+	assert.NoError(t, resolver.Resolve(t.Context(), missing, fakes.Namespace))
+
+	assert.False(t, found.IsBroken())
+	assert.Equal(t, "StreamingJobTemplate large-job not found", missing.Problem)
+}
@@ -1,58 +1,126 @@
 package test_app
 
 import (
-	"flag"
 	"io"
 	"log/slog"
-	"os"
-	"s-vitaliy/kubectl-plugin-arcane/internal/app"
 	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
 	"s-vitaliy/kubectl-plugin-arcane/internal/client/api/common"
-	"strings"
+	"s-vitaliy/kubectl-plugin-arcane/test/fakes"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"go.uber.org/dig"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
-var container *dig.Container
+func newDiscoveryService(t *testing.T, objects ...*unstructured.Unstructured) abstractions.ApiSettingsDiscoverer {
+	client := fakes.NewDynamicClient(t, objects...)
+	return common.ProvideStreamClassDiscoveryService(slog.New(slog.NewTextHandler(io.Discard, nil)), client)
+}
+
+func newMockStreamClass(name string) *unstructured.Unstructured {
+	return fakes.NewStreamClass(name, "streaming.sneaksanddata.com", "v1beta1", "microsoft-sql-server-streams")
+}
+
+func TestDiscoveryFromStreamClassReadsSettings(t *testing.T) {
+	service := newDiscoveryService(t, newMockStreamClass(fakes.StreamClass))
+
+	settings, err := service.DiscoveryFromStreamClass(t.Context(), fakes.StreamClass, fakes.Namespace)
 
-func TestDiscoveryFromStreamClass(t *testing.T) {
-	err := container.Provide(common.ProvideDynamicClient)
 	assert.NoError(t, err)
+	assert.Equal(t, fakes.StreamSettings.ToGroupVersionResource(), settings.ToGroupVersionResource())
+}
+
+func TestDiscoveryFromJobsReadsAnnotations(t *testing.T) {
+	service := newDiscoveryService(t, fakes.NewJob("mock-mssql-stream"))
+
+	settings, err := service.DiscoveryFromJobs(t.Context(), "mock-mssql-stream", fakes.Namespace)
 
-	err = container.Provide(common.ProvideStreamClassDiscoveryService)
 	assert.NoError(t, err)
+	assert.Equal(t, fakes.StreamSettings.ToGroupVersionResource(), settings.ToGroupVersionResource())
+}
+
+func TestDiscoveryFromStreamId(t *testing.T) {
+	service := newDiscoveryService(t,
+		newMockStreamClass(fakes.StreamClass),
+		fakes.NewStreamClass("arcane-stream-other", "streaming.sneaksanddata.com", "v1beta1", "other-streams"),
+		fakes.NewStream("mock-mssql-stream", "Suspended", "suspended"))
+
+	settings, streamClass, err := service.DiscoveryFromStreamId(t.Context(), "mock-mssql-stream", fakes.Namespace)
 
-	err = container.Provide(func() *slog.Logger {
-		handler := slog.NewTextHandler(io.Discard, nil)
-		return slog.New(handler)
-	})
 	assert.NoError(t, err)
+	assert.Equal(t, fakes.StreamClass, streamClass)
+	assert.Equal(t, fakes.StreamSettings.ToGroupVersionResource(), settings.ToGroupVersionResource())
+}
+
+func TestDiscoveryFromStreamIdSkipsBrokenStreamClass(t *testing.T) {
+	broken := newMockStreamClass("arcane-stream-broken")
+	unstructured.RemoveNestedField(broken.Object, "spec", "pluralName")
+	service := newDiscoveryService(t,
+		broken,
+		newMockStreamClass(fakes.StreamClass),
+		fakes.NewStream("mock-mssql-stream", "Running", ""))
 
-	err = container.Invoke(func(service abstractions.ApiSettingsDiscoverer) {
-		assert.NotNil(t, service)
-		api, err := service.DiscoveryFromStreamClass(t.Context(), "arcane-stream-microsoft-sql-server", "arcane")
+	settings, streamClass, err := service.DiscoveryFromStreamId(t.Context(), "mock-mssql-stream", fakes.Namespace)
 
-		assert.NoError(t, err)
-		assert.NotNil(t, api)
-		assert.Equal(t, "streaming.sneaksanddata.com", api.ToGroupVersionResource().Group)
-		assert.Equal(t, "v1beta1", api.ToGroupVersionResource().GroupVersion().Version)
-		assert.Equal(t, "microsoft-sql-server-streams", api.ToGroupVersionResource().Resource)
-	})
 	assert.NoError(t, err)
+	assert.Equal(t, fakes.StreamClass, streamClass)
+	assert.Equal(t, fakes.StreamSettings.ToGroupVersionResource(), settings.ToGroupVersionResource())
+}
 
+func TestDiscoveryFromStreamIdReportsMissingStream(t *testing.T) {
+	service := newDiscoveryService(t, newMockStreamClass(fakes.StreamClass))
+
+	_, _, err := service.DiscoveryFromStreamId(t.Context(), "mock-mssql-stream", fakes.Namespace)
+
+	var notFound *abstractions.StreamNotFoundError
+	assert.ErrorAs(t, err, &notFound)
 }
 
-var cmd = flag.String("cmd", "/opt/homebrew/bin/kind get kubeconfig", "Command to get kubeconfig")
+func TestDiscoveryFromStreamIdReportsAmbiguousStream(t *testing.T) {
+	service := newDiscoveryService(t,
+		newMockStreamClass(fakes.StreamClass),
+		newMockStreamClass("arcane-stream-microsoft-sql-server-copy"),
+		fakes.NewStream("mock-mssql-stream", "Running", ""))
+
+	_, _, err := service.DiscoveryFromStreamId(t.Context(), "mock-mssql-stream", fakes.Namespace)
+
+	var ambiguous *abstractions.AmbiguousStreamError
+	assert.ErrorAs(t, err, &ambiguous)
+	assert.ElementsMatch(t, []string{fakes.StreamClass, "arcane-stream-microsoft-sql-server-copy"}, ambiguous.StreamClasses)
+}
+
+func TestDiscoveryFromJobsReportsMissingJob(t *testing.T) {
+	service := newDiscoveryService(t)
+
+	_, err := service.DiscoveryFromJobs(t.Context(), "mock-mssql-stream", "arcane")
+
+	assert.ErrorIs(t, err, abstractions.ErrJobNotFound)
+}
+
+func TestDiscoveryFromJobsReportsMissingAnnotations(t *testing.T) {
+	job := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata": map[string]any{
+			"name":        "mock-mssql-stream",
+			"namespace":   "arcane",
+			"annotations": map[string]any{"stream.arcane.sneaksanddata.com/api-group": "streaming.sneaksanddata.com"},
+		},
+	}}
+	service := newDiscoveryService(t, job)
+
+	_, err := service.DiscoveryFromJobs(t.Context(), "mock-mssql-stream", "arcane")
+
+	assert.ErrorIs(t, err, abstractions.ErrAnnotationMissing)
+	assert.NotErrorIs(t, err, abstractions.ErrJobNotFound)
+}
 
-func TestMain(m *testing.M) {
-	flag.Parse()
-	command := strings.Split(*cmd, " ")
+func TestDiscoveryFromStreamClassReportsMissingClass(t *testing.T) {
+	service := newDiscoveryService(t)
 
-	container = dig.New()
-	container.Provide(app.NewValidatedExecConfigReaderProvider(&command[0], command[1:]))
+	_, err := service.DiscoveryFromStreamClass(t.Context(), "arcane-stream-missing", "arcane")
 
-	code := m.Run()
-	os.Exit(code)
+	var notFound *abstractions.StreamClassNotFoundError
+	assert.ErrorAs(t, err, &notFound)
+	assert.Equal(t, "arcane-stream-missing", notFound.StreamClass)
 }
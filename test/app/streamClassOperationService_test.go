@@ -0,0 +1,208 @@
+package test_app
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	v0 "s-vitaliy/kubectl-plugin-arcane/internal/client/api/v0"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"s-vitaliy/kubectl-plugin-arcane/test/fakes"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+var streamSettings = fakes.StreamSettings
+
+func newOperator(client dynamic.Interface) abstractions.StreamClassOperator {
+	return v0.ProvideStreamClassOperationService(client, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestWaitForStatusSurvivesClosedWatch(t *testing.T) {
+	client := fakes.NewDynamicClient(t, fakes.NewStream("mock-mssql-stream", "Running", ""))
+	var watches atomic.Int32
+	client.PrependWatchReactor("*", func(action clienttesting.Action) (bool, watch.Interface, error) {
+		if watches.Add(1) > 1 {
+			return false, nil, nil
+		}
+		// The API server ends the first watch right away
+		watcher := watch.NewFake()
+		watcher.Stop()
+		return true, watcher, nil
+	})
+	operator := newOperator(client)
+
+	go func() {
+		for watches.Load() < 2 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		fakes.SetPhase(t, client, "mock-mssql-stream", "Suspended")
+	}()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+	defer cancel()
+	err := operator.WaitForStatus(ctx, abstractions.WaitCondition{Phase: abstractions.StreamPhaseSuspended}, "mock-mssql-stream", "arcane", streamSettings)
+
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, watches.Load(), int32(2))
+}
+
+func TestWaitForStatusTimesOut(t *testing.T) {
+	client := fakes.NewDynamicClient(t, fakes.NewStream("mock-mssql-stream", "Running", ""))
+	operator := newOperator(client)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 200*time.Millisecond)
+	defer cancel()
+	err := operator.WaitForStatus(ctx, abstractions.WaitCondition{Phase: abstractions.StreamPhaseSuspended}, "mock-mssql-stream", "arcane", streamSettings)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestWaitForStatusAlreadyReached(t *testing.T) {
+	client := fakes.NewDynamicClient(t, fakes.NewStream("mock-mssql-stream", "Suspended", ""))
+	operator := newOperator(client)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	err := operator.WaitForStatus(ctx, abstractions.WaitCondition{Phase: abstractions.StreamPhaseSuspended}, "mock-mssql-stream", "arcane", streamSettings)
+
+	assert.NoError(t, err)
+}
+
+func TestWaitForStatusPassedTransientPhase(t *testing.T) {
+	client := fakes.NewDynamicClient(t, fakes.NewStream("mock-mssql-stream", "Running", ""))
+	operator := newOperator(client)
+	patch, err := operator.Backfill(t.Context(), "mock-mssql-stream", "arcane", streamSettings, models.DryRunNone)
+	assert.NoError(t, err)
+
+	// The backfill completes before the wait starts, the operator removes the reload request when it is done
+	fakes.UpdateStream(t, client, "mock-mssql-stream", func(stream *unstructured.Unstructured) {
+		stream.SetAnnotations(nil)
+		stream.Object["status"] = map[string]any{"phase": "Running"}
+	})
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	err = operator.WaitForStatus(ctx, abstractions.WaitCondition{
+		Phase:        abstractions.StreamPhaseBackfill,
+		Patch:        patch,
+		PassedPhases: []abstractions.StreamPhase{abstractions.StreamPhaseRunning},
+	}, "mock-mssql-stream", "arcane", streamSettings)
+
+	assert.NoError(t, err)
+}
+
+func TestWaitForStatusPatchNotObserved(t *testing.T) {
+	client := fakes.NewDynamicClient(t, fakes.NewStream("mock-mssql-stream", "Running", ""))
+	operator := newOperator(client)
+	patch, err := operator.Backfill(t.Context(), "mock-mssql-stream", "arcane", streamSettings, models.DryRunNone)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 200*time.Millisecond)
+	defer cancel()
+	err = operator.WaitForStatus(ctx, abstractions.WaitCondition{
+		Phase:        abstractions.StreamPhaseBackfill,
+		Patch:        patch,
+		PassedPhases: []abstractions.StreamPhase{abstractions.StreamPhaseRunning},
+	}, "mock-mssql-stream", "arcane", streamSettings)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestWaitForStatusIgnoresUnrelatedWrite(t *testing.T) {
+	client := fakes.NewDynamicClient(t, fakes.NewStream("mock-mssql-stream", "Running", ""))
+	operator := newOperator(client)
+	patch, err := operator.Backfill(t.Context(), "mock-mssql-stream", "arcane", streamSettings, models.DryRunNone)
+	assert.NoError(t, err)
+
+	// The stream is written by someone else before the operator reacts to the reload request
+	fakes.UpdateStream(t, client, "mock-mssql-stream", func(stream *unstructured.Unstructured) {
+		stream.SetLabels(map[string]string{"team": "data"})
+		stream.Object["status"] = map[string]any{"phase": "Running", "observedGeneration": int64(1)}
+	})
+
+	ctx, cancel := context.WithTimeout(t.Context(), 200*time.Millisecond)
+	defer cancel()
+	err = operator.WaitForStatus(ctx, abstractions.WaitCondition{
+		Phase:        abstractions.StreamPhaseBackfill,
+		Patch:        patch,
+		PassedPhases: []abstractions.StreamPhase{abstractions.StreamPhaseRunning},
+	}, "mock-mssql-stream", "arcane", streamSettings)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestWaitForStatusFailsFast(t *testing.T) {
+	client := fakes.NewDynamicClient(t, fakes.NewStream("mock-mssql-stream", "Suspended", "suspended"))
+	operator := newOperator(client)
+	patch, err := operator.Resume(t.Context(), "mock-mssql-stream", "arcane", streamSettings, models.DryRunNone)
+	assert.NoError(t, err)
+
+	go fakes.UpdateStream(t, client, "mock-mssql-stream", func(stream *unstructured.Unstructured) {
+		stream.Object["status"] = map[string]any{
+			"phase":   "Failed",
+			"message": "source is unreachable",
+			"conditions": []any{
+				map[string]any{"type": "Error", "status": "True", "reason": "SourceError", "message": "connection refused"},
+			},
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+	defer cancel()
+	err = operator.WaitForStatus(ctx, abstractions.WaitCondition{Phase: abstractions.StreamPhaseRunning, Patch: patch}, "mock-mssql-stream", "arcane", streamSettings)
+
+	var failedErr *abstractions.StreamFailedError
+	assert.ErrorAs(t, err, &failedErr)
+	assert.NoError(t, ctx.Err())
+	assert.Equal(t, "Failed", failedErr.Phase)
+	assert.Equal(t, "source is unreachable", failedErr.Message)
+	assert.Len(t, failedErr.Conditions, 1)
+	assert.Contains(t, err.Error(), "condition Error=True SourceError: connection refused")
+}
+
+func TestWaitForStatusIgnoresFailureBeforePatch(t *testing.T) {
+	client := fakes.NewDynamicClient(t, fakes.NewStream("mock-mssql-stream", "Failed", ""))
+	operator := newOperator(client)
+	patch, err := operator.Resume(t.Context(), "mock-mssql-stream", "arcane", streamSettings, models.DryRunNone)
+	assert.NoError(t, err)
+
+	// The stale failure is written again before the operator reacts to the patch
+	fakes.UpdateStream(t, client, "mock-mssql-stream", func(stream *unstructured.Unstructured) {
+		stream.Object["status"] = map[string]any{"phase": "Failed", "message": "source is unreachable"}
+	})
+
+	ctx, cancel := context.WithTimeout(t.Context(), 200*time.Millisecond)
+	defer cancel()
+	err = operator.WaitForStatus(ctx, abstractions.WaitCondition{Phase: abstractions.StreamPhaseRunning, Patch: patch}, "mock-mssql-stream", "arcane", streamSettings)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestWaitForStatusFollowsPhaseDriver(t *testing.T) {
+	client := fakes.NewDynamicClient(t, fakes.NewStream("mock-mssql-stream", "Running", ""))
+	operator := newOperator(client)
+	patch, err := operator.Backfill(t.Context(), "mock-mssql-stream", fakes.Namespace, streamSettings, models.DryRunNone)
+	assert.NoError(t, err)
+	done := fakes.NewPhaseDriver(t, client, 20*time.Millisecond).Run("mock-mssql-stream", "Reloading", "Running")
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	err = operator.WaitForStatus(ctx, abstractions.WaitCondition{
+		Phase:        abstractions.StreamPhaseBackfill,
+		Patch:        patch,
+		PassedPhases: []abstractions.StreamPhase{abstractions.StreamPhaseRunning},
+	}, "mock-mssql-stream", fakes.Namespace, streamSettings)
+	assert.NoError(t, err)
+
+	err = operator.WaitForStatus(ctx, abstractions.WaitCondition{Phase: abstractions.StreamPhaseRunning}, "mock-mssql-stream", fakes.Namespace, streamSettings)
+	assert.NoError(t, err)
+	<-done
+}
@@ -0,0 +1,48 @@
+package test_app
+
+import (
+	"s-vitaliy/kubectl-plugin-arcane/internal/app"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTransition(t *testing.T) {
+	cases := []struct {
+		operation app.StreamOperationKind
+		phase     string
+		state     string
+		expected  app.TransitionDecision
+	}{
+		{app.OperationSuspend, "Running", "", app.TransitionAllowed},
+		{app.OperationSuspend, "Failed", "", app.TransitionAllowed},
+		{app.OperationSuspend, "Suspended", "suspended", app.TransitionNoOp},
+		{app.OperationSuspend, "Running", "suspended", app.TransitionNoOp},
+		{app.OperationSuspend, "Suspended", "", app.TransitionAllowed},
+		{app.OperationResume, "Suspended", "suspended", app.TransitionAllowed},
+		{app.OperationResume, "Running", "", app.TransitionNoOp},
+		{app.OperationResume, "Failed", "", app.TransitionRejected},
+		{app.OperationResume, "Running", "reload-requested", app.TransitionRejected},
+		{app.OperationBackfill, "Running", "", app.TransitionAllowed},
+		{app.OperationBackfill, "Suspended", "suspended", app.TransitionAllowed},
+		{app.OperationBackfill, "Failed", "", app.TransitionRejected},
+		{app.OperationBackfill, "Reloading", "", app.TransitionNoOp},
+		{app.OperationBackfill, "Running", "reload-requested", app.TransitionNoOp},
+		{app.OperationRestart, "Running", "", app.TransitionAllowed},
+		{app.OperationRestart, "Failed", "", app.TransitionAllowed},
+		{app.OperationRestart, "Reloading", "", app.TransitionRejected},
+		{app.OperationRestart, "Suspended", "suspended", app.TransitionRejected},
+		{app.OperationRestart, "", "", app.TransitionAllowed},
+	}
+
+	for _, c := range cases {
+		t.Run(string(c.operation)+"/"+c.phase+"/"+c.state, func(t *testing.T) {
+			transition := app.ValidateTransition(c.operation, c.phase, c.state)
+
+			assert.Equal(t, c.expected, transition.Decision)
+			if c.expected != app.TransitionAllowed {
+				assert.NotEmpty(t, transition.Reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package test_app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"s-vitaliy/kubectl-plugin-arcane/test/fakes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newHandler(t *testing.T, discoverer abstractions.ApiSettingsDiscoverer, operator abstractions.StreamClassOperator) abstractions.StreamCommandHandler {
+	jobs := &fakes.JobInspector{Jobs: map[string]*models.JobStatus{}}
+	handler, err := app.ProvideStreamCommandHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), discoverer, operator, jobs, nil, nil, nil, nil, nil)
+	assert.NoError(t, err)
+	return handler
+}
+
+func TestDiscoverFallsBackToStreamIdWhenJobNotFound(t *testing.T) {
+	discoverer := fakes.NewDiscoverer("mock-mssql-stream")
+	delete(discoverer.Jobs, "mock-mssql-stream")
+	operator := fakes.NewStreamOperator(fakes.NewStreamSummary("mock-mssql-stream", "Suspended", "suspended"))
+
+	status, err := newHandler(t, discoverer, operator).Status(t.Context(), "mock-mssql-stream", fakes.Namespace, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, fakes.StreamClass, status.StreamClass)
+	assert.Equal(t, []string{"DiscoveryFromJobs", "DiscoveryFromStreamId"}, discoverer.Calls())
+}
+
+func TestStatusResolvesStreamClassDiscoveredFromJob(t *testing.T) {
+	discoverer := fakes.NewDiscoverer("mock-mssql-stream")
+	operator := fakes.NewStreamOperator(fakes.NewStreamSummary("mock-mssql-stream", "Running", ""))
+
+	status, err := newHandler(t, discoverer, operator).Status(t.Context(), "mock-mssql-stream", fakes.Namespace, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, fakes.StreamClass, status.StreamClass)
+	assert.Equal(t, []string{"DiscoveryFromJobs", "ListStreamClasses", "DiscoveryFromStreamClass"}, discoverer.Calls())
+}
+
+func TestDiscoverFallsBackToStreamClassWhenJobNotFound(t *testing.T) {
+	discoverer := fakes.NewDiscoverer("mock-mssql-stream")
+	delete(discoverer.Jobs, "mock-mssql-stream")
+	operator := fakes.NewStreamOperator(fakes.NewStreamSummary("mock-mssql-stream", "Suspended", "suspended"))
+
+	status, err := newHandler(t, discoverer, operator).Status(t.Context(), "mock-mssql-stream", fakes.Namespace, fakes.StreamClass)
+
+	assert.NoError(t, err)
+	assert.Equal(t, fakes.StreamClass, status.StreamClass)
+	assert.Equal(t, []string{"DiscoveryFromJobs", "DiscoveryFromStreamClass"}, discoverer.Calls())
+}
+
+func TestDiscoverDoesNotFallBackOnOtherErrors(t *testing.T) {
+	cases := map[string]error{
+		"annotation missing": fmt.Errorf("%w: job mock-mssql-stream", abstractions.ErrAnnotationMissing),
+		"not found message":  fmt.Errorf("stream class not found"),
+	}
+	for name, jobErr := range cases {
+		t.Run(name, func(t *testing.T) {
+			discoverer := fakes.NewDiscoverer("mock-mssql-stream")
+			discoverer.JobErrors["mock-mssql-stream"] = jobErr
+			operator := fakes.NewStreamOperator(fakes.NewStreamSummary("mock-mssql-stream", "Running", ""))
+
+			_, err := newHandler(t, discoverer, operator).Status(t.Context(), "mock-mssql-stream", fakes.Namespace, "")
+
+			assert.ErrorIs(t, err, jobErr)
+			assert.Equal(t, []string{"DiscoveryFromJobs"}, discoverer.Calls())
+		})
+	}
+}
+
+func TestDiscoverReportsMissingStreamClass(t *testing.T) {
+	discoverer := fakes.NewDiscoverer()
+	operator := fakes.NewStreamOperator()
+
+	_, err := newHandler(t, discoverer, operator).Status(t.Context(), "mock-mssql-stream", fakes.Namespace, "arcane-stream-missing")
+
+	var notFound *abstractions.StreamClassNotFoundError
+	assert.ErrorAs(t, err, &notFound)
+	assert.Equal(t, app.ExitCodeStreamClassNotFound, app.ExitCode(err))
+}
+
+func TestSuspendSkipsSuspendedStream(t *testing.T) {
+	operator := fakes.NewStreamOperator(fakes.NewStreamSummary("mock-mssql-stream", "Suspended", "suspended"))
+
+	result, err := newHandler(t, fakes.NewDiscoverer("mock-mssql-stream"), operator).Suspend(t.Context(), "mock-mssql-stream", fakes.Namespace, "", models.OperationOptions{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "unchanged", result.Operation)
+	assert.Empty(t, operator.Patches())
+}
+
+func TestBackfillRejectsFailedStream(t *testing.T) {
+	operator := fakes.NewStreamOperator(fakes.NewStreamSummary("mock-mssql-stream", "Failed", ""))
+
+	_, err := newHandler(t, fakes.NewDiscoverer("mock-mssql-stream"), operator).Backfill(t.Context(), "mock-mssql-stream", fakes.Namespace, "", models.OperationOptions{Wait: true})
+
+	var invalid *app.InvalidTransitionError
+	assert.ErrorAs(t, err, &invalid)
+	assert.Empty(t, operator.Patches())
+}
+
+func TestBackfillWaitsForCompletion(t *testing.T) {
+	operator := fakes.NewStreamOperator(fakes.NewStreamSummary("mock-mssql-stream", "Running", ""))
+	operator.Reaction = func(operator *fakes.StreamOperator, id string, state string) {
+		operator.SetPhase(id, "Reloading")
+		time.Sleep(10 * time.Millisecond)
+		operator.SetPhase(id, "Running")
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	result, err := newHandler(t, fakes.NewDiscoverer("mock-mssql-stream"), operator).Backfill(ctx, "mock-mssql-stream", fakes.Namespace, "", models.OperationOptions{Wait: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "backfilled", result.Operation)
+	assert.Equal(t, "Running", result.Phase)
+	assert.Equal(t, []string{"backfill mock-mssql-stream"}, operator.Patches())
+}
+
+func TestBackfillAcceptsPassedReloading(t *testing.T) {
+	operator := fakes.NewStreamOperator(fakes.NewStreamSummary("mock-mssql-stream", "Running", ""))
+	// The backfill is over before the wait starts, Reloading is never seen
+	operator.Reaction = func(operator *fakes.StreamOperator, id string, state string) {
+		operator.CompleteReload(id)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	result, err := newHandler(t, fakes.NewDiscoverer("mock-mssql-stream"), operator).Backfill(ctx, "mock-mssql-stream", fakes.Namespace, "", models.OperationOptions{Wait: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "backfilled", result.Operation)
+}
+
+func TestBackfillReportsFailure(t *testing.T) {
+	operator := fakes.NewStreamOperator(fakes.NewStreamSummary("mock-mssql-stream", "Running", ""))
+	operator.Reaction = func(operator *fakes.StreamOperator, id string, state string) {
+		operator.SetFailed(id, "source is unreachable")
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	_, err := newHandler(t, fakes.NewDiscoverer("mock-mssql-stream"), operator).Backfill(ctx, "mock-mssql-stream", fakes.Namespace, "", models.OperationOptions{Wait: true})
+
+	var failed *abstractions.StreamFailedError
+	assert.ErrorAs(t, err, &failed)
+	assert.Equal(t, "source is unreachable", failed.Message)
+	assert.NoError(t, ctx.Err())
+	assert.Equal(t, app.ExitCodeStreamFailed, app.ExitCode(err))
+}
+
+func TestRestartSuspendsAndResumes(t *testing.T) {
+	operator := fakes.NewStreamOperator(fakes.NewStreamSummary("mock-mssql-stream", "Running", ""))
+	operator.Reaction = func(operator *fakes.StreamOperator, id string, state string) {
+		if state == "suspended" {
+			operator.SetPhase(id, "Suspended")
+		} else {
+			operator.SetPhase(id, "Running")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	result, err := newHandler(t, fakes.NewDiscoverer("mock-mssql-stream"), operator).Restart(ctx, "mock-mssql-stream", fakes.Namespace, "", models.OperationOptions{Wait: true})
+	operator.WaitReactions()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "restarted", result.Operation)
+	assert.Equal(t, "Running", result.Phase)
+	assert.Equal(t, []string{"suspend mock-mssql-stream", "resume mock-mssql-stream"}, operator.Patches())
+}
+
+func TestRestartTimesOutWhenStreamIsNotSuspended(t *testing.T) {
+	operator := fakes.NewStreamOperator(fakes.NewStreamSummary("mock-mssql-stream", "Running", ""))
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+	_, err := newHandler(t, fakes.NewDiscoverer("mock-mssql-stream"), operator).Restart(ctx, "mock-mssql-stream", fakes.Namespace, "", models.OperationOptions{Wait: true})
+
+	var timeout *abstractions.WaitTimeoutError
+	assert.ErrorAs(t, err, &timeout)
+	assert.Equal(t, "Suspended", timeout.Phase)
+	assert.Equal(t, app.ExitCodeTimeout, app.ExitCode(err))
+	assert.Equal(t, []string{"suspend mock-mssql-stream"}, operator.Patches())
+}
+
+func TestDryRunDoesNotPatch(t *testing.T) {
+	operator := fakes.NewStreamOperator(fakes.NewStreamSummary("mock-mssql-stream", "Running", ""))
+
+	result, err := newHandler(t, fakes.NewDiscoverer("mock-mssql-stream"), operator).Restart(t.Context(), "mock-mssql-stream", fakes.Namespace, "", models.OperationOptions{Wait: true, DryRun: models.DryRunClient})
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Patches, 2)
+	assert.Empty(t, operator.Patches())
+}
+
+func TestBackfillReportsProgress(t *testing.T) {
+	operator := fakes.NewStreamOperator(fakes.NewStreamSummary("mock-mssql-stream", "Running", ""))
+	operator.Reaction = func(operator *fakes.StreamOperator, id string, state string) {
+		time.Sleep(50 * time.Millisecond)
+		operator.SetPhase(id, "Reloading")
+		operator.CompleteReload(id)
+	}
+	var reports []*models.WaitProgress
+	options := models.OperationOptions{Wait: true, Progress: func(progress *models.WaitProgress) {
+		reports = append(reports, progress)
+	}}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	_, err := newHandler(t, fakes.NewDiscoverer("mock-mssql-stream"), operator).Backfill(ctx, "mock-mssql-stream", fakes.Namespace, "", options)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, reports)
+	assert.Equal(t, "backfill", reports[0].Operation)
+	assert.Equal(t, "Reloading", reports[0].TargetPhase)
+	assert.False(t, reports[0].Deadline.IsZero())
+}
+
+func TestDeleteDoesNotSuspendSuspendedStream(t *testing.T) {
+	discoverer := fakes.NewDiscoverer("mock-mssql-stream")
+	operator := fakes.NewStreamOperator(fakes.NewStreamSummary("mock-mssql-stream", "Suspended", "suspended"))
+
+	result, err := newHandler(t, discoverer, operator).Delete(t.Context(), "mock-mssql-stream", fakes.Namespace, "", models.DeleteOptions{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "deleted", result.Operation)
+	assert.Equal(t, []string{"delete mock-mssql-stream"}, operator.Patches())
+}
+
+func TestDeleteIgnoresFailureWhileSuspending(t *testing.T) {
+	discoverer := fakes.NewDiscoverer("mock-mssql-stream")
+	operator := fakes.NewStreamOperator(fakes.NewStreamSummary("mock-mssql-stream", "Running", ""))
+	// The stream fails while its job is stopped and is suspended afterwards
+	operator.Reaction = func(operator *fakes.StreamOperator, id string, state string) {
+		operator.SetFailed(id, "job was killed")
+		time.Sleep(50 * time.Millisecond)
+		operator.SetPhase(id, "Suspended")
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	result, err := newHandler(t, discoverer, operator).Delete(ctx, "mock-mssql-stream", fakes.Namespace, "", models.DeleteOptions{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "deleted", result.Operation)
+	assert.Equal(t, []string{"suspend mock-mssql-stream", "delete mock-mssql-stream"}, operator.Patches())
+}
@@ -0,0 +1,64 @@
+package test_bundle
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"s-vitaliy/kubectl-plugin-arcane/internal/bundle"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newBundle() *models.StreamBundle {
+	return &models.StreamBundle{Objects: []*unstructured.Unstructured{
+		{Object: map[string]any{"apiVersion": "v1", "kind": "StreamClass", "metadata": map[string]any{"name": "sql"}}},
+		{Object: map[string]any{"apiVersion": "v1", "kind": "SqlStream", "metadata": map[string]any{"name": "users"}, "spec": map[string]any{"fetchSize": int64(1024)}}},
+	}}
+}
+
+func TestWriteSeparatesDocuments(t *testing.T) {
+	var out bytes.Buffer
+
+	assert.NoError(t, bundle.Write(&out, newBundle()))
+
+	assert.Equal(t, "apiVersion: v1\nkind: StreamClass\nmetadata:\n  name: sql\n---\n"+
+		"apiVersion: v1\nkind: SqlStream\nmetadata:\n  name: users\nspec:\n  fetchSize: 1024\n", out.String())
+}
+
+func TestReadFileWrittenByWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundle.yaml")
+	var out bytes.Buffer
+	assert.NoError(t, bundle.Write(&out, newBundle()))
+	assert.NoError(t, os.WriteFile(path, append([]byte("---\n"), out.Bytes()...), 0o644))
+
+	read, err := bundle.Read(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, newBundle(), read)
+}
+
+func TestReadDirWrittenByWriteDir(t *testing.T) {
+	dir := t.TempDir()
+	paths, err := bundle.WriteDir(dir, newBundle())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "streamclass-sql.yaml"), filepath.Join(dir, "sqlstream-users.yaml")}, paths)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# not a manifest"), 0o644))
+
+	read, err := bundle.Read(dir)
+
+	assert.NoError(t, err)
+	// The files are read in the order of their names
+	assert.Equal(t, []string{"users", "sql"}, []string{read.Objects[0].GetName(), read.Objects[1].GetName()})
+}
+
+func TestReadRejectsObjectsWithoutKind(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundle.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("metadata:\n  name: users\n"), 0o644))
+
+	_, err := bundle.Read(path)
+
+	assert.ErrorContains(t, err, "without the kind or the name")
+}
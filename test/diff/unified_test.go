@@ -0,0 +1,56 @@
+package test_diff
+
+import (
+	"s-vitaliy/kubectl-plugin-arcane/internal/diff"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedEqual(t *testing.T) {
+	assert.Equal(t, "", diff.Unified("a", "b", "x\ny\n", "x\ny\n"))
+}
+
+func TestUnifiedChange(t *testing.T) {
+	from := "a\nb\nc\nd\ne\nf\ng\nh\n"
+	to := "a\nb\nc\nd\nE\nf\ng\nh\n"
+
+	expected := `--- live
++++ local
+@@ -2,7 +2,7 @@
+ b
+ c
+ d
+-e
++E
+ f
+ g
+ h
+`
+	assert.Equal(t, expected, diff.Unified("live", "local", from, to))
+}
+
+func TestUnifiedSeparateHunks(t *testing.T) {
+	from := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	to := "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n"
+
+	expected := `--- a
++++ b
+@@ -1,3 +1,4 @@
++0
+ 1
+ 2
+ 3
+@@ -9,4 +10,3 @@
+ 9
+ 10
+ 11
+-12
+`
+	assert.Equal(t, expected, diff.Unified("a", "b", from, to))
+}
+
+func TestUnifiedFromEmpty(t *testing.T) {
+	expected := "--- a\n+++ b\n@@ -0,0 +1,1 @@\n+x\n"
+	assert.Equal(t, expected, diff.Unified("a", "b", "", "x\n"))
+}
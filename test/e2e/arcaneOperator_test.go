@@ -0,0 +1,119 @@
+package test_e2e
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"s-vitaliy/kubectl-plugin-arcane/test/fakes"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	streamId          = "mock-mssql-stream"
+	eventuallyTimeout = 5 * time.Second
+	eventuallyTick    = 10 * time.Millisecond
+)
+
+var metav1GetOptions = metav1.GetOptions{}
+
+func newCluster(t *testing.T, phase string, state string) (dynamic.Interface, *fakes.Cli) {
+	client, cli, _ := fakes.NewOperatedCli(t, fakes.NewDefaultStreamClass(), fakes.NewStream(streamId, phase, state))
+	return client, cli
+}
+
+func phaseOf(t *testing.T, client dynamic.Interface) string {
+	stream, err := client.Resource(fakes.StreamSettings.ToGroupVersionResource()).Namespace(fakes.Namespace).Get(context.Background(), streamId, metav1.GetOptions{})
+	assert.NoError(t, err)
+	phase, _, _ := unstructured.NestedString(stream.Object, "status", "phase")
+	return phase
+}
+
+func jobExists(t *testing.T, client dynamic.Interface) bool {
+	_, err := client.Resource(fakes.JobResource).Namespace(fakes.Namespace).Get(context.Background(), streamId, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false
+	}
+	assert.NoError(t, err)
+	return true
+}
+
+func TestOperatorReconcilesExistingStreams(t *testing.T) {
+	client, _ := newCluster(t, "Running", "suspended")
+
+	assert.Eventually(t, func() bool {
+		return phaseOf(t, client) == "Suspended"
+	}, eventuallyTimeout, eventuallyTick)
+	assert.False(t, jobExists(t, client))
+}
+
+func TestStreamLifecycle(t *testing.T) {
+	client, cli := newCluster(t, "Suspended", "suspended")
+
+	out, err := cli.Run("stream", "resume", streamId)
+	assert.NoError(t, err)
+	assert.Contains(t, out, streamId)
+	assert.Eventually(t, func() bool {
+		return phaseOf(t, client) == "Running" && jobExists(t, client)
+	}, eventuallyTimeout, eventuallyTick)
+
+	// The stream class is discovered from the job created by the operator
+	out, err = cli.Run("stream", "backfill", streamId, "--wait", "--deadline", "10s")
+	assert.NoError(t, err)
+	assert.Contains(t, out, streamId)
+	assert.Equal(t, "Running", phaseOf(t, client))
+
+	out, err = cli.Run("stream", "restart", streamId, "--wait", "--deadline", "10s")
+	assert.NoError(t, err)
+	assert.Contains(t, out, streamId)
+	assert.Equal(t, "Running", phaseOf(t, client))
+	assert.True(t, jobExists(t, client))
+
+	out, err = cli.Run("stream", "status", streamId, "-o", "name")
+	assert.NoError(t, err)
+	assert.Contains(t, out, streamId)
+
+	_, err = cli.Run("stream", "suspend", streamId)
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return phaseOf(t, client) == "Suspended" && !jobExists(t, client)
+	}, eventuallyTimeout, eventuallyTick)
+}
+
+func TestBackfillClearsReloadRequest(t *testing.T) {
+	client, cli := newCluster(t, "Running", "")
+
+	_, err := cli.Run("stream", "backfill", streamId, "--class", fakes.StreamClass, "--wait", "--deadline", "10s")
+	assert.NoError(t, err)
+
+	// The operator completes the reload in the same update that moves the stream back to Running
+	stream, err := client.Resource(fakes.StreamSettings.ToGroupVersionResource()).Namespace(fakes.Namespace).Get(context.Background(), streamId, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, stream.GetAnnotations()["arcane/state"])
+	assert.Equal(t, "Running", phaseOf(t, client))
+}
+
+func TestDeprecatedPositionalArguments(t *testing.T) {
+	client, cli := newCluster(t, "Suspended", "suspended")
+
+	_, err := cli.Run("stream", "resume", streamId, fakes.StreamClass)
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return phaseOf(t, client) == "Running"
+	}, eventuallyTimeout, eventuallyTick)
+
+	_, err = cli.Run("stream", "backfill", streamId, fakes.StreamClass, "10s", "--wait")
+	assert.NoError(t, err)
+	assert.Equal(t, "Running", phaseOf(t, client))
+
+	_, err = cli.Run("stream", "backfill", streamId, fakes.StreamClass, "soon")
+	assert.ErrorContains(t, err, "failed to parse deadline soon")
+
+	_, err = cli.Run("stream", "resume", streamId, fakes.StreamClass, "--class", "other-class")
+	assert.ErrorContains(t, err, "conflicts with --class other-class")
+}
@@ -0,0 +1,125 @@
+package test_e2e
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"s-vitaliy/kubectl-plugin-arcane/test/fakes"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+)
+
+// newExportedCluster creates the cluster with the running stream created from testdata/stream.yaml and its job template.
+func newExportedCluster(t *testing.T) (dynamic.Interface, *fakes.Cli) {
+	client, cli := newCreatedCluster(t, true)
+	_, err := client.Resource(fakes.JobTemplateResource).Namespace(fakes.Namespace).Create(t.Context(), fakes.NewJobTemplate("standard-job"), metav1.CreateOptions{})
+	assert.NoError(t, err)
+	return client, cli
+}
+
+// newBlankCluster creates the cluster with the stream resource definition only.
+func newBlankCluster(t *testing.T) (dynamic.Interface, *fakes.Cli) {
+	client, cli, _ := fakes.NewOperatedCli(t, fakes.NewStreamDefinition())
+	return client, cli
+}
+
+func TestExportWritesStreamWithReferencedObjects(t *testing.T) {
+	_, cli := newExportedCluster(t)
+
+	out, err := cli.Run("stream", "export", streamId)
+
+	assert.NoError(t, err)
+	assert.Contains(t, out, "kind: StreamClass\n")
+	assert.Contains(t, out, "---\napiVersion: streaming.sneaksanddata.com/v1\nkind: StreamingJobTemplate\nmetadata:\n  name: standard-job\n")
+	assert.Contains(t, out, "---\napiVersion: streaming.sneaksanddata.com/v1beta1\nkind: MicrosoftSqlServerStream\n")
+	assert.NotContains(t, out, "namespace:")
+	assert.NotContains(t, out, "resourceVersion:")
+	assert.NotContains(t, out, "status:")
+	assert.NotContains(t, out, "Secret")
+}
+
+func TestExportFailsOnMissingReference(t *testing.T) {
+	_, cli := newCreatedCluster(t, false)
+
+	_, err := cli.Run("stream", "export", "--all")
+
+	assert.ErrorContains(t, err, "StreamingJobTemplate standard-job referenced by stream mock-mssql-stream not found")
+}
+
+func TestImportIsIdempotent(t *testing.T) {
+	_, source := newExportedCluster(t)
+	dir := filepath.Join(t.TempDir(), "bundle")
+	out, err := source.Run("stream", "export", "--all", "--to-dir", dir)
+	assert.NoError(t, err)
+	assert.Contains(t, out, "3 exported, 0 failed\n")
+	files, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 3)
+
+	target, cli := newBlankCluster(t)
+	out, err = cli.Run("stream", "import", "-f", dir)
+	assert.NoError(t, err)
+	assert.Equal(t, "KIND                       NAME                                 RESULT\n"+
+		"StreamClass                arcane-stream-microsoft-sql-server   created\n"+
+		"StreamingJobTemplate       standard-job                         created\n"+
+		"MicrosoftSqlServerStream   mock-mssql-stream                    created\n"+
+		"3 created, 0 failed\n", out)
+	assert.Equal(t, "suspended", getStream(t, target).GetAnnotations()["arcane/state"])
+
+	out, err = cli.Run("stream", "import", "-f", dir)
+	assert.NoError(t, err)
+	assert.Contains(t, out, "3 unchanged, 0 failed\n")
+}
+
+func TestImportUpdatesChangedStreamKeepingLiveState(t *testing.T) {
+	client, cli := newExportedCluster(t)
+	file := filepath.Join(t.TempDir(), "bundle.yaml")
+	_, err := cli.Run("stream", "export", streamId, "--to-file", file)
+	assert.NoError(t, err)
+	_, err = cli.Run("stream", "set", streamId, "sourceSettings.fetchSize=5000")
+	assert.NoError(t, err)
+
+	out, err := cli.Run("stream", "import", "-f", file, "--dry-run", "client")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "2 unchanged, 1 updated, 0 failed (dry run: client)\n")
+	assert.Equal(t, int64(5000), specField(t, client, "sourceSettings", "fetchSize"))
+
+	_, err = cli.Run("stream", "import", "-f", file)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1024), specField(t, client, "sourceSettings", "fetchSize"))
+	assert.Empty(t, getStream(t, client).GetAnnotations()["arcane/state"])
+}
+
+func TestImportKeepsBundleState(t *testing.T) {
+	_, source := newExportedCluster(t)
+	file := filepath.Join(t.TempDir(), "bundle.yaml")
+	_, err := source.Run("stream", "export", streamId, "--to-file", file)
+	assert.NoError(t, err)
+
+	target, cli := newBlankCluster(t)
+	_, err = cli.Run("stream", "import", "-f", file, "--keep-state")
+
+	assert.NoError(t, err)
+	assert.Empty(t, getStream(t, target).GetAnnotations()["arcane/state"])
+	assert.Eventually(t, func() bool { return phaseOf(t, target) == "Running" }, eventuallyTimeout, eventuallyTick)
+}
+
+func TestImportDryRunUsesBundleStreamClass(t *testing.T) {
+	_, source := newExportedCluster(t)
+	file := filepath.Join(t.TempDir(), "bundle.yaml")
+	_, err := source.Run("stream", "export", streamId, "--to-file", file)
+	assert.NoError(t, err)
+
+	target, cli := newBlankCluster(t)
+	out, err := cli.Run("stream", "import", "-f", file, "--dry-run", "client")
+
+	assert.NoError(t, err)
+	assert.Contains(t, out, "MicrosoftSqlServerStream   mock-mssql-stream                    created\n")
+	assert.Contains(t, out, "3 created, 0 failed (dry run: client)\n")
+	streamClasses, err := target.Resource(fakes.StreamClassResource).Namespace(fakes.Namespace).List(t.Context(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, streamClasses.Items)
+}
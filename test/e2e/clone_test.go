@@ -0,0 +1,102 @@
+package test_e2e
+
+import (
+	"testing"
+
+	"s-vitaliy/kubectl-plugin-arcane/internal/app"
+	"s-vitaliy/kubectl-plugin-arcane/test/fakes"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const cloneId = "mock-mssql-stream-orders"
+
+func getStreamIn(t *testing.T, client dynamic.Interface, namespace string, name string) *unstructured.Unstructured {
+	stream, err := client.Resource(fakes.StreamSettings.ToGroupVersionResource()).Namespace(namespace).Get(t.Context(), name, metav1GetOptions)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	assert.NoError(t, err)
+	return stream
+}
+
+func TestCloneCreatesSuspendedCopyWithOverrides(t *testing.T) {
+	client, cli := newCreatedCluster(t, true)
+
+	out, err := cli.Run("stream", "clone", streamId, cloneId, "--set", "sourceSettings.table=orders", "--set", "sinkSettings.targetTableName=orders_stream")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "stream/mock-mssql-stream-orders created (copy of "+fakes.Namespace+"/mock-mssql-stream)\n", out)
+	clone := getStreamIn(t, client, fakes.Namespace, cloneId)
+	if assert.NotNil(t, clone) {
+		assert.Equal(t, "suspended", clone.GetAnnotations()["arcane/state"])
+		table, _, _ := unstructured.NestedString(clone.Object, "spec", "sourceSettings", "table")
+		assert.Equal(t, "orders", table)
+		fetchSize, _, _ := unstructured.NestedInt64(clone.Object, "spec", "sourceSettings", "fetchSize")
+		assert.Equal(t, int64(1024), fetchSize)
+	}
+	assert.Equal(t, "users", specField(t, client, "sourceSettings", "table"))
+	assert.Equal(t, "Running", phaseOf(t, client))
+}
+
+func TestCloneRejectsInvalidOverrides(t *testing.T) {
+	client, cli := newCreatedCluster(t, false)
+
+	_, err := cli.Run("stream", "clone", streamId, cloneId, "--set", "sourceSettings.fetchSize=many")
+
+	assert.Equal(t, app.ExitCodeInvalidManifest, app.ExitCode(err))
+	assert.Nil(t, getStreamIn(t, client, fakes.Namespace, cloneId))
+}
+
+func TestCloneInDryRunPrintsCopy(t *testing.T) {
+	client, cli := newCreatedCluster(t, true)
+
+	out, err := cli.Run("stream", "clone", streamId, cloneId, "--dry-run", "client")
+
+	assert.NoError(t, err)
+	assert.Contains(t, out, "name: "+cloneId+"\n")
+	assert.Contains(t, out, "arcane/state: suspended\n")
+	assert.NotContains(t, out, "uid:")
+	assert.NotContains(t, out, "resourceVersion:")
+	assert.NotContains(t, out, "status:")
+	assert.Nil(t, getStreamIn(t, client, fakes.Namespace, cloneId))
+}
+
+func TestCloneToNamespace(t *testing.T) {
+	client, cli := newCreatedCluster(t, false)
+	// The stream classes are namespaced, the copy is created by the stream class of the target namespace
+	streamClass := fakes.NewDefaultStreamClass()
+	streamClass.SetNamespace("staging")
+	_, err := client.Resource(fakes.StreamClassResource).Namespace("staging").Create(t.Context(), streamClass, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	_, err = cli.Run("stream", "clone", streamId, cloneId, "--to-namespace", "staging")
+
+	assert.NoError(t, err)
+	assert.NotNil(t, getStreamIn(t, client, "staging", cloneId))
+	assert.Nil(t, getStreamIn(t, client, fakes.Namespace, cloneId))
+}
+
+func TestCloneToContext(t *testing.T) {
+	client, cli := newCreatedCluster(t, true)
+	target, _ := newEmptyCluster(t)
+	cli.AddContext("target", target, fake.NewClientset())
+
+	_, err := cli.Run("stream", "clone", streamId, streamId, "--to-context", "target")
+
+	assert.NoError(t, err)
+	clone := getStreamIn(t, target, fakes.Namespace, streamId)
+	if assert.NotNil(t, clone) {
+		assert.Equal(t, "suspended", clone.GetAnnotations()["arcane/state"])
+	}
+	assert.Equal(t, "Running", phaseOf(t, client))
+
+	_, err = cli.Run("stream", "clone", streamId, cloneId, "--to-context", "missing")
+	assert.ErrorContains(t, err, "failed to connect to context missing")
+}
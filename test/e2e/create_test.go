@@ -0,0 +1,143 @@
+package test_e2e
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"s-vitaliy/kubectl-plugin-arcane/internal/app"
+	"s-vitaliy/kubectl-plugin-arcane/test/fakes"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+func newEmptyCluster(t *testing.T) (dynamic.Interface, *fakes.Cli) {
+	client, cli, _ := fakes.NewOperatedCli(t, fakes.NewStreamDefinition(), fakes.NewDefaultStreamClass())
+	return client, cli
+}
+
+func getStream(t *testing.T, client dynamic.Interface) *unstructured.Unstructured {
+	stream, err := client.Resource(fakes.StreamSettings.ToGroupVersionResource()).Namespace(fakes.Namespace).Get(t.Context(), streamId, metav1GetOptions)
+	assert.NoError(t, err)
+	return stream
+}
+
+func TestCreateFromFileStartsSuspended(t *testing.T) {
+	client, cli := newEmptyCluster(t)
+
+	out, err := cli.Run("stream", "create", fakes.StreamClass, streamId, "-f", "testdata/stream.yaml")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "stream/mock-mssql-stream created\n", out)
+	stream := getStream(t, client)
+	assert.Equal(t, "suspended", stream.GetAnnotations()["arcane/state"])
+	assert.Equal(t, fakes.Namespace, stream.GetNamespace())
+	table, _, _ := unstructured.NestedString(stream.Object, "spec", "sourceSettings", "table")
+	assert.Equal(t, "users", table)
+	assert.False(t, jobExists(t, client))
+}
+
+func TestCreateRunning(t *testing.T) {
+	client, cli := newEmptyCluster(t)
+
+	_, err := cli.Run("stream", "create", fakes.StreamClass, streamId, "-f", "testdata/stream.yaml", "--running")
+
+	assert.NoError(t, err)
+	assert.Empty(t, getStream(t, client).GetAnnotations()["arcane/state"])
+	assert.Eventually(t, func() bool {
+		return phaseOf(t, client) == "Running" && jobExists(t, client)
+	}, eventuallyTimeout, eventuallyTick)
+}
+
+func TestCreateRejectsInvalidManifest(t *testing.T) {
+	client, cli := newEmptyCluster(t)
+	data, err := os.ReadFile("testdata/stream.yaml")
+	assert.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "stream.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(strings.Replace(string(data), "fetchSize: 1024", "fetchSize: many", 1)), 0o600))
+
+	_, err = cli.Run("stream", "create", fakes.StreamClass, streamId, "-f", path)
+
+	assert.ErrorContains(t, err, "spec.sourceSettings.fetchSize")
+	assert.Equal(t, app.ExitCodeInvalidManifest, app.ExitCode(err))
+	_, err = client.Resource(fakes.StreamSettings.ToGroupVersionResource()).Namespace(fakes.Namespace).Get(t.Context(), streamId, metav1GetOptions)
+	assert.Error(t, err)
+}
+
+func TestCreateAsksRequiredValues(t *testing.T) {
+	client, cli := newEmptyCluster(t)
+	cli.Input = strings.Join([]string{"db-credentials", "dbo", "users", "users_stream", "standard-job", "large-job"}, "\n") + "\n"
+
+	_, err := cli.Run("stream", "create", fakes.StreamClass, streamId)
+
+	assert.NoError(t, err)
+	spec, _, _ := unstructured.NestedMap(getStream(t, client).Object, "spec")
+	assert.Equal(t, map[string]any{
+		"connectionStringRef":    map[string]any{"name": "db-credentials"},
+		"sourceSettings":         map[string]any{"schema": "dbo", "table": "users", "fetchSize": int64(1024)},
+		"sinkSettings":           map[string]any{"targetTableName": "users_stream"},
+		"jobTemplateRef":         map[string]any{"apiGroup": "streaming.sneaksanddata.com", "kind": "StreamingJobTemplate", "name": "standard-job"},
+		"backfillJobTemplateRef": map[string]any{"apiGroup": "streaming.sneaksanddata.com", "kind": "StreamingJobTemplate", "name": "large-job"},
+	}, spec)
+}
+
+func TestCreateRequiresFileWithoutTerminal(t *testing.T) {
+	_, cli := newEmptyCluster(t)
+
+	_, err := cli.Run("stream", "create", fakes.StreamClass, streamId)
+
+	assert.ErrorContains(t, err, "--from-file")
+}
+
+func TestCreateTemplateAndDryRun(t *testing.T) {
+	client, cli := newEmptyCluster(t)
+
+	out, err := cli.Run("stream", "create", fakes.StreamClass, streamId, "--template")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "kind: MicrosoftSqlServerStream")
+	assert.Contains(t, out, "arcane/state: suspended")
+	assert.Contains(t, out, "fetchSize: 1024")
+
+	out, err = cli.Run("stream", "create", fakes.StreamClass, streamId, "-f", "testdata/stream.yaml", "--dry-run", "client")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out, "stream/mock-mssql-stream created (dry run: client)\n"))
+	assert.Contains(t, out, "targetTableName: users_stream")
+	_, err = client.Resource(fakes.StreamSettings.ToGroupVersionResource()).Namespace(fakes.Namespace).Get(t.Context(), streamId, metav1GetOptions)
+	assert.Error(t, err)
+}
+
+func TestCreateRunningFromTemplate(t *testing.T) {
+	client, cli := newEmptyCluster(t)
+	template, err := cli.Run("stream", "create", fakes.StreamClass, streamId, "--template")
+	assert.NoError(t, err)
+	// The template keeps the suspended state annotation, only the required values are filled in
+	manifest := strings.NewReplacer(
+		`name: ""`, "name: standard",
+		`schema: ""`, "schema: dbo",
+		`table: ""`, "table: users",
+		`targetTableName: ""`, "targetTableName: users_stream",
+	).Replace(template)
+	path := filepath.Join(t.TempDir(), "stream.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(manifest), 0o600))
+
+	_, err = cli.Run("stream", "create", fakes.StreamClass, streamId, "-f", path, "--running")
+
+	assert.NoError(t, err)
+	assert.Empty(t, getStream(t, client).GetAnnotations()["arcane/state"])
+	assert.Eventually(t, func() bool {
+		return phaseOf(t, client) == "Running" && jobExists(t, client)
+	}, eventuallyTimeout, eventuallyTick)
+}
+
+func TestCreateRunningAsksRequiredValues(t *testing.T) {
+	client, cli := newEmptyCluster(t)
+	cli.Input = strings.Join([]string{"db-credentials", "dbo", "users", "users_stream", "standard-job", "large-job"}, "\n") + "\n"
+
+	_, err := cli.Run("stream", "create", fakes.StreamClass, streamId, "--running")
+
+	assert.NoError(t, err)
+	assert.Empty(t, getStream(t, client).GetAnnotations()["arcane/state"])
+}
@@ -0,0 +1,134 @@
+package test_e2e
+
+import (
+	"testing"
+
+	"s-vitaliy/kubectl-plugin-arcane/internal/app"
+	"s-vitaliy/kubectl-plugin-arcane/test/fakes"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// deletion is the state of the stream observed when its resource was deleted.
+type deletion struct {
+	phase     string
+	jobExists bool
+}
+
+// newDeletionCluster creates the cluster with the running stream and records the state of the stream at its deletion.
+func newDeletionCluster(t *testing.T) (dynamic.Interface, *fakes.Cli, *[]deletion) {
+	client, cli, _ := fakes.NewOperatedCli(t, fakes.NewDefaultStreamClass(), fakes.NewStream(streamId, "Running", ""))
+	deletions := &[]deletion{}
+	// The reactors run under the lock of the fake client, so the state is read from its tracker
+	tracker := client.Tracker()
+	client.PrependReactor("delete", fakes.StreamSettings.ToGroupVersionResource().Resource, func(action clienttesting.Action) (bool, runtime.Object, error) {
+		stream, err := tracker.Get(fakes.StreamSettings.ToGroupVersionResource(), fakes.Namespace, streamId)
+		assert.NoError(t, err)
+		phase, _, _ := unstructured.NestedString(stream.(*unstructured.Unstructured).Object, "status", "phase")
+		_, err = tracker.Get(fakes.JobResource, fakes.Namespace, streamId)
+		*deletions = append(*deletions, deletion{phase: phase, jobExists: err == nil})
+		return false, nil, nil
+	})
+	assert.Eventually(t, func() bool { return jobExists(t, client) }, eventuallyTimeout, eventuallyTick)
+	return client, cli, deletions
+}
+
+func streamExists(t *testing.T, client dynamic.Interface) bool {
+	_, err := client.Resource(fakes.StreamSettings.ToGroupVersionResource()).Namespace(fakes.Namespace).Get(t.Context(), streamId, metav1GetOptions)
+	if apierrors.IsNotFound(err) {
+		return false
+	}
+	assert.NoError(t, err)
+	return true
+}
+
+func TestDeleteStopsStreamBeforeDeletingIt(t *testing.T) {
+	client, cli, deletions := newDeletionCluster(t)
+
+	out, err := cli.Run("stream", "delete", streamId, "--yes", "--wait")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "stream/mock-mssql-stream deleted\n", out)
+	assert.Equal(t, []deletion{{phase: "Suspended", jobExists: false}}, *deletions)
+	assert.False(t, streamExists(t, client))
+	assert.False(t, jobExists(t, client))
+}
+
+func TestDeleteForceSkipsTeardown(t *testing.T) {
+	client, cli, deletions := newDeletionCluster(t)
+
+	_, err := cli.Run("stream", "delete", streamId, "--yes", "--force")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []deletion{{phase: "Running", jobExists: true}}, *deletions)
+	assert.False(t, streamExists(t, client))
+}
+
+func TestDeleteAsksForConfirmation(t *testing.T) {
+	client, cli, _ := newDeletionCluster(t)
+
+	_, err := cli.Run("stream", "delete", streamId)
+	assert.ErrorContains(t, err, "--yes")
+
+	cli.Input = "n\n"
+	_, err = cli.Run("stream", "delete", streamId)
+	assert.ErrorContains(t, err, "deletion of stream mock-mssql-stream is cancelled")
+	assert.True(t, streamExists(t, client))
+	assert.Equal(t, "Running", phaseOf(t, client))
+
+	cli.Input = "y\n"
+	_, err = cli.Run("stream", "delete", streamId)
+	assert.NoError(t, err)
+	assert.False(t, streamExists(t, client))
+}
+
+func TestDeleteInDryRunKeepsStream(t *testing.T) {
+	client, cli, deletions := newDeletionCluster(t)
+
+	out, err := cli.Run("stream", "delete", streamId, "--dry-run", "client")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "stream/mock-mssql-stream deleted (dry run: client)\n"+
+		"PATCH microsoft-sql-server-streams.v1beta1.streaming.sneaksanddata.com/mock-mssql-stream (application/merge-patch+json)\n"+
+		`{"metadata":{"annotations":{"arcane/state":"suspended"}}}`+"\n", out)
+	assert.Empty(t, *deletions)
+	assert.True(t, streamExists(t, client))
+	assert.Equal(t, "Running", phaseOf(t, client))
+}
+
+func TestDeleteTimeoutExitsWithTimeoutCode(t *testing.T) {
+	for _, resource := range []string{fakes.StreamSettings.ToGroupVersionResource().Resource, fakes.JobResource.Resource} {
+		t.Run(resource, func(t *testing.T) {
+			client, cli, _ := newDeletionCluster(t)
+			// The resource is kept by a finalizer that is never removed
+			client.(*dynamicfake.FakeDynamicClient).PrependReactor("delete", resource, func(action clienttesting.Action) (bool, runtime.Object, error) {
+				return true, nil, nil
+			})
+
+			_, err := cli.Run("stream", "delete", streamId, "--yes", "--wait", "--deadline", "300ms")
+
+			assert.ErrorContains(t, err, "timed out waiting for stream mock-mssql-stream")
+			assert.Equal(t, app.ExitCodeTimeout, app.ExitCode(err))
+		})
+	}
+}
+
+func TestDeleteFailedStream(t *testing.T) {
+	client, cli, operator := fakes.NewOperatedCli(t, fakes.NewDefaultStreamClass(), fakes.NewStream(streamId, "Running", ""))
+	assert.Eventually(t, func() bool { return jobExists(t, client) }, eventuallyTimeout, eventuallyTick)
+	operator.Fail(t.Context(), streamId, "source is unreachable")
+	assert.Equal(t, "Failed", phaseOf(t, client))
+
+	out, err := cli.Run("stream", "delete", streamId, "--yes", "--wait", "--deadline", "10s")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "stream/mock-mssql-stream deleted\n", out)
+	assert.False(t, streamExists(t, client))
+	assert.False(t, jobExists(t, client))
+}
@@ -0,0 +1,57 @@
+package test_e2e
+
+import (
+	"encoding/json"
+	"testing"
+
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"s-vitaliy/kubectl-plugin-arcane/test/fakes"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newDescribedCluster(t *testing.T) *fakes.Cli {
+	stream := fakes.NewStream(streamId, "Running", "")
+	stream.Object["spec"] = map[string]any{
+		"connectionStringRef":    map[string]any{"name": "db-credentials"},
+		"jobTemplateRef":         map[string]any{"apiGroup": "streaming.sneaksanddata.com", "kind": "StreamingJobTemplate", "name": "standard-job"},
+		"backfillJobTemplateRef": map[string]any{"apiGroup": "streaming.sneaksanddata.com", "kind": "StreamingJobTemplate", "name": "large-job"},
+		"sourceTable":            "dbo.orders",
+	}
+	_, cli, _ := fakes.NewOperatedCli(t,
+		fakes.NewDefaultStreamClass(),
+		fakes.NewSecret("db-credentials", map[string]string{"connectionString": "Server=db;Password=s3cr3t"}),
+		fakes.NewJobTemplate("standard-job"),
+		stream)
+	return cli
+}
+
+func TestDescribeResolvesReferences(t *testing.T) {
+	cli := newDescribedCluster(t)
+
+	out, err := cli.Run("stream", "describe", streamId, "-o", "json")
+	assert.NoError(t, err)
+
+	var description models.StreamDescription
+	assert.NoError(t, json.Unmarshal([]byte(out), &description))
+	assert.Equal(t, fakes.StreamClass, description.StreamClass)
+	assert.Equal(t, "dbo.orders", description.Spec["sourceTable"])
+	assert.Equal(t, []models.StreamReference{
+		{Kind: models.ReferenceKindStreamClass, Name: fakes.StreamClass},
+		{Field: "spec.connectionStringRef", Kind: models.ReferenceKindSecret, Name: "db-credentials", Keys: []string{"connectionString"}},
+		{Field: "spec.jobTemplateRef", Kind: models.ReferenceKindJobTemplate, Name: "standard-job"},
+		{Field: "spec.backfillJobTemplateRef", Kind: models.ReferenceKindJobTemplate, Name: "large-job", Problem: "StreamingJobTemplate large-job not found"},
+	}, description.References)
+}
+
+func TestDescribeRedactsSecretValues(t *testing.T) {
+	cli := newDescribedCluster(t)
+
+	out, err := cli.Run("stream", "describe", streamId)
+
+	assert.NoError(t, err)
+	assert.Contains(t, out, "sourceTable: dbo.orders")
+	assert.Contains(t, out, "OK (keys: connectionString)")
+	assert.Contains(t, out, "BROKEN: StreamingJobTemplate large-job not found")
+	assert.NotContains(t, out, "s3cr3t")
+}
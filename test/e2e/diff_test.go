@@ -0,0 +1,82 @@
+package test_e2e
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"s-vitaliy/kubectl-plugin-arcane/internal/app"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffIgnoresServerFieldsAndState(t *testing.T) {
+	_, cli := newCreatedCluster(t, false)
+
+	out, err := cli.Run("stream", "diff", "-f", "testdata/stream.yaml")
+
+	assert.NoError(t, err)
+	assert.Empty(t, out)
+}
+
+func TestDiffAppliesSchemaDefaults(t *testing.T) {
+	_, cli := newCreatedCluster(t, false)
+	data, err := os.ReadFile("testdata/stream.yaml")
+	assert.NoError(t, err)
+	// The live stream has the default values the manifest leaves out
+	manifest := strings.Replace(string(data), "    fetchSize: 1024\n", "", 1)
+	path := filepath.Join(t.TempDir(), "stream.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(manifest), 0o600))
+
+	out, err := cli.Run("stream", "diff", "-f", path)
+
+	assert.NoError(t, err)
+	assert.Empty(t, out)
+}
+
+func TestDiffShowsChangedFields(t *testing.T) {
+	_, cli := newCreatedCluster(t, true)
+	_, err := cli.Run("stream", "set", streamId, "sourceSettings.fetchSize=5000")
+	assert.NoError(t, err)
+
+	out, err := cli.Run("stream", "diff", "-f", "testdata/stream.yaml")
+
+	assert.Equal(t, app.ExitCodeStreamDiffers, app.ExitCode(err))
+	assert.Equal(t, "--- live/mock-mssql-stream\n+++ local/mock-mssql-stream\n"+
+		"@@ -16,6 +16,6 @@\n"+
+		"   sinkSettings:\n"+
+		"     targetTableName: users_stream\n"+
+		"   sourceSettings:\n"+
+		"-    fetchSize: 5000\n"+
+		"+    fetchSize: 1024\n"+
+		"     schema: dbo\n"+
+		"     table: users\n", out)
+
+	out, _ = cli.Run("stream", "diff", "-f", "testdata/stream.yaml", "--color", "always")
+	assert.Contains(t, out, "\x1b[31m-    fetchSize: 5000\x1b[0m\n\x1b[32m+    fetchSize: 1024\x1b[0m\n")
+}
+
+func TestDiffIncludesStateOnRequest(t *testing.T) {
+	_, cli := newCreatedCluster(t, false)
+
+	out, err := cli.Run("stream", "diff", "-f", "testdata/stream.yaml", "--include-state")
+
+	assert.Equal(t, app.ExitCodeStreamDiffers, app.ExitCode(err))
+	assert.Contains(t, out, "-  annotations:\n-    arcane/state: suspended\n")
+}
+
+func TestDiffOfMissingStreamAddsManifest(t *testing.T) {
+	_, cli := newEmptyCluster(t)
+
+	out, err := cli.Run("stream", "diff", "-f", "testdata/stream.yaml", "-o", "json")
+
+	assert.Equal(t, app.ExitCodeStreamDiffers, app.ExitCode(err))
+	var result models.StreamDiff
+	assert.NoError(t, json.Unmarshal([]byte(out), &result))
+	assert.False(t, result.Exists)
+	assert.Equal(t, "arcane-stream-microsoft-sql-server", result.StreamClass)
+	assert.Contains(t, result.Diff, "+kind: MicrosoftSqlServerStream\n")
+}
@@ -0,0 +1,70 @@
+package test_e2e
+
+import (
+	"testing"
+
+	"s-vitaliy/kubectl-plugin-arcane/internal/app"
+	"s-vitaliy/kubectl-plugin-arcane/test/fakes"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const copyStreamClass = "arcane-stream-microsoft-sql-server-copy"
+
+// newAmbiguousCluster creates the cluster where the stream resource is served by two stream classes.
+func newAmbiguousCluster(t *testing.T) *fakes.Cli {
+	client := fakes.NewDynamicClient(t,
+		fakes.NewDefaultStreamClass(),
+		fakes.NewStreamClass(copyStreamClass, "streaming.sneaksanddata.com", "v1beta1", "microsoft-sql-server-streams"),
+		fakes.NewStream(streamId, "Running", ""))
+	return fakes.NewCli(client, fake.NewClientset(), fakes.Namespace)
+}
+
+func TestDiscoveryReportsMissingStream(t *testing.T) {
+	_, cli := newEmptyCluster(t)
+
+	_, err := cli.Run("stream", "status", streamId)
+
+	assert.ErrorContains(t, err, "mock-mssql-stream")
+	assert.Equal(t, app.ExitCodeStreamNotFound, app.ExitCode(err))
+}
+
+func TestDiscoveryReportsAmbiguousStream(t *testing.T) {
+	cli := newAmbiguousCluster(t)
+
+	_, err := cli.Run("stream", "status", streamId)
+
+	assert.ErrorContains(t, err, "exists in several stream classes")
+	assert.ErrorContains(t, err, copyStreamClass)
+	assert.ErrorContains(t, err, "--class")
+}
+
+func TestDiscoveryUsesStreamClassFlag(t *testing.T) {
+	cli := newAmbiguousCluster(t)
+
+	out, err := cli.Run("stream", "status", streamId, "--class", copyStreamClass, "-o", "jsonpath={.streamClass}")
+
+	assert.NoError(t, err)
+	assert.Equal(t, copyStreamClass+"\n", out)
+}
+
+func TestDiscoveryAsksForStreamClass(t *testing.T) {
+	cli := newAmbiguousCluster(t)
+	cli.Input = "2\n"
+
+	out, err := cli.Run("stream", "status", streamId, "-o", "jsonpath={.streamClass}")
+
+	assert.NoError(t, err)
+	assert.Equal(t, copyStreamClass+"\n", out)
+}
+
+func TestDiscoveryRejectsInvalidChoice(t *testing.T) {
+	cli := newAmbiguousCluster(t)
+	cli.Input = "3\n"
+
+	_, err := cli.Run("stream", "status", streamId)
+
+	assert.ErrorContains(t, err, "exists in several stream classes")
+	assert.ErrorContains(t, err, `invalid stream class choice "3"`)
+}
@@ -0,0 +1,126 @@
+package test_e2e
+
+import (
+	"strings"
+	"testing"
+
+	"s-vitaliy/kubectl-plugin-arcane/internal/app"
+	"s-vitaliy/kubectl-plugin-arcane/test/fakes"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// newCreatedCluster creates the cluster with the stream created from testdata/stream.yaml.
+func newCreatedCluster(t *testing.T, running bool) (dynamic.Interface, *fakes.Cli) {
+	client, cli := newEmptyCluster(t)
+	args := []string{"stream", "create", fakes.StreamClass, streamId, "-f", "testdata/stream.yaml"}
+	if running {
+		args = append(args, "--running")
+	}
+	_, err := cli.Run(args...)
+	assert.NoError(t, err)
+	expected := "Suspended"
+	if running {
+		expected = "Running"
+	}
+	assert.Eventually(t, func() bool {
+		return phaseOf(t, client) == expected
+	}, eventuallyTimeout, eventuallyTick)
+	return client, cli
+}
+
+func specField(t *testing.T, client dynamic.Interface, fields ...string) any {
+	value, _, err := unstructured.NestedFieldNoCopy(getStream(t, client).Object, append([]string{"spec"}, fields...)...)
+	assert.NoError(t, err)
+	return value
+}
+
+func TestSetChangesSpecFields(t *testing.T) {
+	client, cli := newCreatedCluster(t, false)
+
+	out, err := cli.Run("stream", "set", streamId, "sourceSettings.fetchSize=5000", "lookBackInterval=3600")
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out, "stream/mock-mssql-stream updated\n--- live/mock-mssql-stream\n+++ edited/mock-mssql-stream\n"))
+	assert.Contains(t, out, "-    fetchSize: 1024\n+    fetchSize: 5000\n")
+	assert.Contains(t, out, "+  lookBackInterval: 3600\n")
+	assert.Equal(t, int64(5000), specField(t, client, "sourceSettings", "fetchSize"))
+	assert.Equal(t, int64(3600), specField(t, client, "lookBackInterval"))
+}
+
+func TestSetRejectsValuesNotMatchingSchema(t *testing.T) {
+	client, cli := newCreatedCluster(t, false)
+
+	for _, value := range []string{"sourceSettings.fetchSize=many", "sourceSettings.fetchSize=0", "sourceSettings.batchSize=10", "tableProperties.format=CSV"} {
+		_, err := cli.Run("stream", "set", streamId, value)
+		assert.Equal(t, app.ExitCodeInvalidManifest, app.ExitCode(err), value)
+	}
+	assert.Equal(t, int64(1024), specField(t, client, "sourceSettings", "fetchSize"))
+}
+
+func TestSetInDryRunLeavesStreamUnchanged(t *testing.T) {
+	client, cli := newCreatedCluster(t, false)
+
+	out, err := cli.Run("stream", "set", streamId, "sinkSettings.targetTableName=users_v2", "--dry-run", "client")
+
+	assert.NoError(t, err)
+	assert.Contains(t, out, "(dry run: client)")
+	assert.Contains(t, out, `{"metadata":{"resourceVersion":"`)
+	assert.Contains(t, out, `"spec":{"sinkSettings":{"targetTableName":"users_v2"}}`)
+	assert.Contains(t, out, "+    targetTableName: users_v2\n")
+	assert.Equal(t, "users_stream", specField(t, client, "sinkSettings", "targetTableName"))
+}
+
+func TestSetRestartsRunningStream(t *testing.T) {
+	client, cli := newCreatedCluster(t, true)
+
+	out, err := cli.Run("stream", "set", streamId, "sourceSettings.fetchSize=5000", "--restart", "--wait")
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out, "stream/mock-mssql-stream updated and restarted (phase: Running)\n"))
+	assert.True(t, jobExists(t, client))
+	assert.Empty(t, getStream(t, client).GetAnnotations()["arcane/state"])
+}
+
+func TestSetDoesNotRestartSuspendedStream(t *testing.T) {
+	client, cli := newCreatedCluster(t, false)
+
+	out, err := cli.Run("stream", "set", streamId, "sourceSettings.fetchSize=5000", "--restart")
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out, "stream/mock-mssql-stream updated (the stream is suspended, the changes apply when it is resumed)\n"))
+	assert.Equal(t, "Suspended", phaseOf(t, client))
+}
+
+func TestEditAppliesEditorChanges(t *testing.T) {
+	client, cli := newCreatedCluster(t, false)
+	t.Setenv("KUBE_EDITOR", "sed -i s/users_stream/users_v2/")
+
+	out, err := cli.Run("stream", "edit", streamId)
+
+	assert.NoError(t, err)
+	assert.Contains(t, out, "-    targetTableName: users_stream\n+    targetTableName: users_v2\n")
+	assert.Equal(t, "users_v2", specField(t, client, "sinkSettings", "targetTableName"))
+}
+
+func TestEditWithoutChanges(t *testing.T) {
+	_, cli := newCreatedCluster(t, false)
+	t.Setenv("KUBE_EDITOR", "true")
+
+	out, err := cli.Run("stream", "edit", streamId)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "stream/mock-mssql-stream unchanged (no changes)\n", out)
+}
+
+func TestEditRejectsRenamedStream(t *testing.T) {
+	client, cli := newCreatedCluster(t, false)
+	t.Setenv("KUBE_EDITOR", "sed -i s/mock-mssql-stream/other-stream/")
+
+	_, err := cli.Run("stream", "edit", streamId)
+
+	assert.ErrorContains(t, err, "the manifest name other-stream does not match the stream name mock-mssql-stream")
+	assert.Equal(t, "users_stream", specField(t, client, "sinkSettings", "targetTableName"))
+}
@@ -0,0 +1,326 @@
+package fakes
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	stateSuspended       = "suspended"
+	stateReloadRequested = "reload-requested"
+)
+
+// ArcaneOperator is an in-process stand-in for the Arcane operator. It watches the streams of one stream
+// resource through a dynamic client, a fake one or a client of an envtest API server, and reacts
+// to the arcane/state annotation like the operator does:
+//   - suspended: the stream job is deleted and the stream moves to the Suspended phase;
+//   - reload-requested: the stream job is recreated and the stream moves to the Reloading phase,
+//     after the ReloadDuration the annotation is removed and the stream moves to the Running phase;
+//   - no annotation: the stream job is created if missing and the stream moves to the Running phase,
+//     or to the Failed phase if the stream was made to fail with Fail.
+//
+// The stream jobs are named after the streams and annotated with the stream resource, so the CLI
+// can discover the stream class from them.
+type ArcaneOperator struct {
+	// ReloadDuration is how long a stream stays in the Reloading phase.
+	ReloadDuration time.Duration
+
+	client    dynamic.Interface
+	settings  *models.ClientApiSettings
+	namespace string
+
+	mu        sync.Mutex
+	reloading map[string]bool
+	failures  map[string]string
+	errors    []error
+	running   sync.WaitGroup
+}
+
+// NewArcaneOperator creates an operator managing the streams of the resource in the namespace.
+func NewArcaneOperator(client dynamic.Interface, namespace string, settings *models.ClientApiSettings) *ArcaneOperator {
+	return &ArcaneOperator{
+		ReloadDuration: 50 * time.Millisecond,
+		client:         client,
+		settings:       settings,
+		namespace:      namespace,
+		reloading:      map[string]bool{},
+		failures:       map[string]string{},
+	}
+}
+
+// StartArcaneOperator starts an operator managing the streams of the fake cluster for the duration of the test.
+// The test fails if the operator could not reconcile a stream.
+func StartArcaneOperator(t testing.TB, client dynamic.Interface) *ArcaneOperator {
+	operator := NewArcaneOperator(client, Namespace, StreamSettings)
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := operator.Start(ctx); err != nil {
+		cancel()
+		t.Fatalf("failed to start the Arcane operator: %v", err)
+	}
+	t.Cleanup(func() {
+		cancel()
+		operator.Wait()
+		for _, err := range operator.Errors() {
+			t.Errorf("Arcane operator: %v", err)
+		}
+	})
+	return operator
+}
+
+// Start reconciles the existing streams and keeps watching the streams until the context is cancelled.
+func (o *ArcaneOperator) Start(ctx context.Context) error {
+	resource := o.client.Resource(o.settings.ToGroupVersionResource()).Namespace(o.namespace)
+	// The watch is opened before the list, so no change is lost in between
+	watcher, err := resource.Watch(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to watch streams: %w", err)
+	}
+	streams, err := resource.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		watcher.Stop()
+		return fmt.Errorf("failed to list streams: %w", err)
+	}
+	for i := range streams.Items {
+		o.reconcile(ctx, streams.Items[i].GetName())
+	}
+
+	o.running.Add(1)
+	go func() {
+		defer o.running.Done()
+		o.watch(ctx, watcher)
+	}()
+	return nil
+}
+
+// Fail makes the running stream fail with the message, the stream stays in the Failed phase until it is suspended.
+func (o *ArcaneOperator) Fail(ctx context.Context, id string, message string) {
+	o.mu.Lock()
+	o.failures[id] = message
+	o.mu.Unlock()
+	o.reconcile(ctx, id)
+}
+
+// Wait blocks until the operator has stopped after its context was cancelled.
+func (o *ArcaneOperator) Wait() {
+	o.running.Wait()
+}
+
+// Errors returns the errors the operator ran into while reconciling the streams.
+func (o *ArcaneOperator) Errors() []error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]error(nil), o.errors...)
+}
+
+func (o *ArcaneOperator) watch(ctx context.Context, watcher watch.Interface) {
+	resource := o.client.Resource(o.settings.ToGroupVersionResource()).Namespace(o.namespace)
+	for {
+		select {
+		case <-ctx.Done():
+			watcher.Stop()
+			return
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				// The API server closes the watches from time to time
+				var err error
+				if watcher, err = resource.Watch(ctx, metav1.ListOptions{}); err != nil {
+					if ctx.Err() == nil {
+						o.fail(fmt.Errorf("failed to watch streams: %w", err))
+					}
+					return
+				}
+				continue
+			}
+			if event.Type != watch.Added && event.Type != watch.Modified {
+				continue
+			}
+			if stream, ok := event.Object.(*unstructured.Unstructured); ok {
+				o.reconcile(ctx, stream.GetName())
+			}
+		}
+	}
+}
+
+// reconcile moves the stream to the phase requested by its arcane/state annotation.
+// The watch events may be outdated, so the current stream is read first. The changes made by the operator
+// are observed by the watch again, so the steps must be idempotent.
+func (o *ArcaneOperator) reconcile(ctx context.Context, id string) {
+	stream, err := o.client.Resource(o.settings.ToGroupVersionResource()).Namespace(o.namespace).Get(ctx, id, metav1.GetOptions{})
+	if err != nil {
+		o.report(ctx, err)
+		return
+	}
+	phase, _, _ := unstructured.NestedString(stream.Object, "status", "phase")
+
+	switch stream.GetAnnotations()[models.StateAnnotation] {
+	case stateSuspended:
+		if err = o.deleteJob(ctx, id); err == nil && phase != abstractions.StreamPhaseSuspended.String() {
+			err = o.setPhase(ctx, id, abstractions.StreamPhaseSuspended)
+		}
+	case stateReloadRequested:
+		if !o.startReload(id) {
+			return
+		}
+		if err = o.deleteJob(ctx, id); err == nil {
+			err = o.createJob(ctx, id)
+		}
+		if err == nil && phase != abstractions.StreamPhaseBackfill.String() {
+			err = o.setPhase(ctx, id, abstractions.StreamPhaseBackfill)
+		}
+		if err != nil {
+			o.finishReload(id)
+			break
+		}
+		o.running.Add(1)
+		go func() {
+			defer o.running.Done()
+			defer o.finishReload(id)
+			select {
+			case <-ctx.Done():
+			case <-time.After(o.ReloadDuration):
+				o.report(ctx, o.completeReload(ctx, id))
+			}
+		}()
+	default:
+		if message, failed := o.failure(id); failed {
+			if phase != abstractions.StreamPhaseFailed.String() {
+				err = o.setFailed(ctx, id, message)
+			}
+			break
+		}
+		if err = o.createJob(ctx, id); err == nil && phase != abstractions.StreamPhaseRunning.String() {
+			err = o.setPhase(ctx, id, abstractions.StreamPhaseRunning)
+		}
+	}
+	o.report(ctx, err)
+}
+
+// startReload marks the stream as reloading, it returns false if the stream is already reloading.
+func (o *ArcaneOperator) startReload(id string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.reloading[id] {
+		return false
+	}
+	o.reloading[id] = true
+	return true
+}
+
+func (o *ArcaneOperator) finishReload(id string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.reloading, id)
+}
+
+// setPhase changes the phase of the stream.
+func (o *ArcaneOperator) setPhase(ctx context.Context, id string, phase abstractions.StreamPhase) error {
+	return o.updateStream(ctx, id, func(stream *unstructured.Unstructured) (bool, error) {
+		return true, unstructured.SetNestedField(stream.Object, phase.String(), "status", "phase")
+	})
+}
+
+func (o *ArcaneOperator) failure(id string) (string, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	message, failed := o.failures[id]
+	return message, failed
+}
+
+// setFailed moves the stream to the Failed phase with the message and a terminal condition.
+func (o *ArcaneOperator) setFailed(ctx context.Context, id string, message string) error {
+	return o.updateStream(ctx, id, func(stream *unstructured.Unstructured) (bool, error) {
+		stream.Object["status"] = map[string]any{
+			"phase":   abstractions.StreamPhaseFailed.String(),
+			"message": message,
+			"conditions": []any{
+				map[string]any{"type": "Error", "status": "True", "reason": "StreamFailed", "message": message},
+			},
+		}
+		return true, nil
+	})
+}
+
+// completeReload removes the reload request and moves the stream to the Running phase,
+// unless the stream state was changed while reloading.
+func (o *ArcaneOperator) completeReload(ctx context.Context, id string) error {
+	return o.updateStream(ctx, id, func(stream *unstructured.Unstructured) (bool, error) {
+		annotations := stream.GetAnnotations()
+		if annotations[models.StateAnnotation] != stateReloadRequested {
+			return false, nil
+		}
+		delete(annotations, models.StateAnnotation)
+		stream.SetAnnotations(annotations)
+		return true, unstructured.SetNestedField(stream.Object, abstractions.StreamPhaseRunning.String(), "status", "phase")
+	})
+}
+
+// updateStream applies the change to the current stream, retrying on conflicts. The change returns false
+// if the stream should not be updated.
+func (o *ArcaneOperator) updateStream(ctx context.Context, id string, change func(stream *unstructured.Unstructured) (bool, error)) error {
+	resource := o.client.Resource(o.settings.ToGroupVersionResource()).Namespace(o.namespace)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		stream, err := resource.Get(ctx, id, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		changed, err := change(stream)
+		if err != nil || !changed {
+			return err
+		}
+		_, err = resource.Update(ctx, stream, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// createJob creates the stream job unless it exists.
+func (o *ArcaneOperator) createJob(ctx context.Context, id string) error {
+	job := NewJob(id)
+	job.SetNamespace(o.namespace)
+	gvr := o.settings.ToGroupVersionResource()
+	job.SetAnnotations(map[string]string{
+		"stream.arcane.sneaksanddata.com/api-group":       gvr.Group,
+		"stream.arcane.sneaksanddata.com/api-version":     gvr.Version,
+		"stream.arcane.sneaksanddata.com/api-plural-name": gvr.Resource,
+	})
+	_, err := o.client.Resource(JobResource).Namespace(o.namespace).Create(ctx, job, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+// deleteJob deletes the stream job if it exists.
+func (o *ArcaneOperator) deleteJob(ctx context.Context, id string) error {
+	err := o.client.Resource(JobResource).Namespace(o.namespace).Delete(ctx, id, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// report records the reconciliation error, the errors caused by the shutdown and the deleted streams are ignored.
+func (o *ArcaneOperator) report(ctx context.Context, err error) {
+	if err == nil || ctx.Err() != nil || apierrors.IsNotFound(err) {
+		return
+	}
+	o.fail(err)
+}
+
+func (o *ArcaneOperator) fail(err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.errors = append(o.errors, err)
+}
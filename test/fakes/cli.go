@@ -0,0 +1,158 @@
+package fakes
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"s-vitaliy/kubectl-plugin-arcane/internal/app"
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/client/api/common"
+	"s-vitaliy/kubectl-plugin-arcane/internal/commands"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"s-vitaliy/kubectl-plugin-arcane/internal/output"
+	"s-vitaliy/kubectl-plugin-arcane/internal/prompt"
+
+	"github.com/alecthomas/kong"
+	"go.uber.org/dig"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+// Cli runs the commands of the plugin against the clients of a fake cluster, wired the same way as the plugin binary.
+type Cli struct {
+	// Input holds the answers to the questions of the commands, one per line.
+	// The commands run non-interactively if it is empty.
+	Input string
+
+	dynamicClient    dynamic.Interface
+	kubernetesClient kubernetes.Interface
+	namespace        string
+	contexts         map[string]*Cli
+}
+
+// cli mirrors the command line of the plugin binary without the connection flags.
+type cli struct {
+	Output string `short:"o"`
+
+	Stream commands.StreamCmd `cmd:""`
+}
+
+// NewCli creates a command line runner using the clients in the namespace.
+func NewCli(dynamicClient dynamic.Interface, kubernetesClient kubernetes.Interface, namespace string) *Cli {
+	return &Cli{dynamicClient: dynamicClient, kubernetesClient: kubernetesClient, namespace: namespace}
+}
+
+// NewOperatedCli creates the fake cluster with the objects, starts the Arcane operator managing its streams
+// and returns the command line running in the namespace of the cluster.
+func NewOperatedCli(t testing.TB, objects ...*unstructured.Unstructured) (*dynamicfake.FakeDynamicClient, *Cli, *ArcaneOperator) {
+	client := NewDynamicClient(t, objects...)
+	operator := StartArcaneOperator(t, client)
+	return client, NewCli(client, fake.NewClientset(), Namespace), operator
+}
+
+// AddContext adds the kubeconfig context connecting the commands to the clients of another fake cluster.
+func (c *Cli) AddContext(name string, dynamicClient dynamic.Interface, kubernetesClient kubernetes.Interface) {
+	if c.contexts == nil {
+		c.contexts = map[string]*Cli{}
+	}
+	c.contexts[name] = NewCli(dynamicClient, kubernetesClient, c.namespace)
+}
+
+// Run parses and runs the command line, it returns the standard output of the command.
+func (c *Cli) Run(args ...string) (string, error) {
+	var arguments cli
+	parser, err := kong.New(&arguments, kong.Name("kubectl-arcane"), kong.Exit(func(int) {}))
+	if err != nil {
+		return "", err
+	}
+	command, err := parser.Parse(args)
+	if err != nil {
+		return "", err
+	}
+
+	var stdout bytes.Buffer
+	container := dig.New()
+	providers := []any{
+		func() *slog.Logger { return slog.New(slog.NewTextHandler(io.Discard, nil)) },
+		func() (output.Printer, error) { return output.NewPrinter(arguments.Output, &stdout) },
+		func() *output.ProgressView { return output.NewProgressView(io.Discard, 0) },
+		func() *prompt.Prompter {
+			return prompt.NewPrompter(strings.NewReader(c.Input), io.Discard, c.Input != "")
+		},
+	}
+	for _, provider := range providers {
+		if err := container.Provide(provider); err != nil {
+			return "", err
+		}
+	}
+	if err := c.registerProviders(container); err != nil {
+		return "", err
+	}
+
+	err = command.Run(container)
+	return stdout.String(), err
+}
+
+// registerProviders registers the providers of the plugin binary with the clients of the fake cluster
+// replacing the clients connected to a real one. The container must provide the logger.
+func (c *Cli) registerProviders(container *dig.Container) error {
+	if err := app.RegisterProviders(container, &models.ConnectionOptions{Namespace: c.namespace}); err != nil {
+		return err
+	}
+	decorators := []any{
+		func() common.ConfigReader { return &configReader{namespace: c.namespace} },
+		func() dynamic.Interface { return c.dynamicClient },
+		func() kubernetes.Interface { return c.kubernetesClient },
+		func(logger *slog.Logger) abstractions.StreamCommandHandlerFactory {
+			return func(kubeContext string) (abstractions.StreamCommandHandler, error) {
+				return c.newHandler(logger, kubeContext)
+			}
+		},
+	}
+	for _, decorator := range decorators {
+		if err := container.Decorate(decorator); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newHandler creates the stream command handler connected to the fake cluster of the context.
+func (c *Cli) newHandler(logger *slog.Logger, kubeContext string) (abstractions.StreamCommandHandler, error) {
+	other, ok := c.contexts[kubeContext]
+	if !ok {
+		return nil, fmt.Errorf("context %s does not exist", kubeContext)
+	}
+	container := dig.New()
+	err := container.Provide(func() *slog.Logger {
+		return logger
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := other.registerProviders(container); err != nil {
+		return nil, err
+	}
+	return app.ResolveStreamCommandHandler(container)
+}
+
+// configReader reads the namespace of the fake cluster, the clients are provided directly.
+type configReader struct {
+	namespace string
+}
+
+func (r *configReader) ReadConfig() (*rest.Config, error) {
+	return nil, fmt.Errorf("the fake cluster has no connection config")
+}
+
+func (r *configReader) ReadNamespace() (string, error) {
+	return r.namespace, nil
+}
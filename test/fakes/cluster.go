@@ -0,0 +1,402 @@
+package fakes
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// Namespace is the namespace of the objects in the fake cluster.
+	Namespace = "arcane"
+
+	// StreamClass is the name of the stream class of the streams in the fake cluster.
+	StreamClass = "arcane-stream-microsoft-sql-server"
+
+	// StreamKind is the kind of the streams in the fake cluster.
+	StreamKind = "MicrosoftSqlServerStream"
+)
+
+// StreamSettings are the API settings of the streams in the fake cluster.
+var StreamSettings = models.NewClientApiSettings("streaming.sneaksanddata.com", "v1beta1", "microsoft-sql-server-streams")
+
+var (
+	StreamClassResource = schema.GroupVersionResource{Group: "streaming.sneaksanddata.com", Version: "v1beta1", Resource: "stream-classes"}
+	JobResource         = schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}
+	PodResource         = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	SecretResource      = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "secrets"}
+	JobTemplateResource = schema.GroupVersionResource{Group: "streaming.sneaksanddata.com", Version: "v1", Resource: "streaming-job-templates"}
+	CrdResource         = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+)
+
+// NewDynamicClient creates a fake dynamic client serving the stream classes, job templates, jobs, pods, secrets and streams.
+// The objects are created in the resources matching their kinds, the objects of unknown kinds are streams.
+// Unlike the plain fake client, the client maintains the resource versions like the API server does:
+// every change increases the version, an update of a stale version fails with a conflict and a watch
+// starts from the requested version.
+func NewDynamicClient(t testing.TB, objects ...*unstructured.Unstructured) *dynamicfake.FakeDynamicClient {
+	listKinds := map[schema.GroupVersionResource]string{
+		StreamClassResource:                     "StreamClassList",
+		JobResource:                             "JobList",
+		PodResource:                             "PodList",
+		SecretResource:                          "SecretList",
+		JobTemplateResource:                     "StreamingJobTemplateList",
+		CrdResource:                             "CustomResourceDefinitionList",
+		StreamSettings.ToGroupVersionResource(): StreamKind + "List",
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+	tracker := &versionedTracker{ObjectTracker: client.Tracker()}
+	client.PrependReactor("*", "*", clienttesting.ObjectReaction(tracker))
+	client.PrependWatchReactor("*", func(action clienttesting.Action) (bool, watch.Interface, error) {
+		return tracker.watchFrom(action, listKinds)
+	})
+	for _, object := range objects {
+		_, err := client.Resource(resourceOf(object)).Namespace(object.GetNamespace()).Create(context.Background(), object, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+	return client
+}
+
+func resourceOf(object *unstructured.Unstructured) schema.GroupVersionResource {
+	switch object.GetKind() {
+	case "StreamClass":
+		return StreamClassResource
+	case "Job":
+		return JobResource
+	case "Pod":
+		return PodResource
+	case "Secret":
+		return SecretResource
+	case "StreamingJobTemplate":
+		return JobTemplateResource
+	case "CustomResourceDefinition":
+		return CrdResource
+	default:
+		return StreamSettings.ToGroupVersionResource()
+	}
+}
+
+// NewStreamClass creates a stream class object referencing the stream resource.
+func NewStreamClass(name string, apiGroup string, apiVersion string, pluralName string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "streaming.sneaksanddata.com/v1beta1",
+		"kind":       "StreamClass",
+		"metadata":   map[string]any{"name": name, "namespace": Namespace},
+		"spec": map[string]any{
+			"apiGroupRef": apiGroup,
+			"apiVersion":  apiVersion,
+			"pluralName":  pluralName,
+		},
+	}}
+}
+
+// NewDefaultStreamClass creates the stream class of the streams in the fake cluster.
+func NewDefaultStreamClass() *unstructured.Unstructured {
+	return NewStreamClass(StreamClass, "streaming.sneaksanddata.com", "v1beta1", "microsoft-sql-server-streams")
+}
+
+// NewJob creates a job object of the stream annotated with the stream resource.
+func NewJob(id string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata": map[string]any{
+			"name":      id,
+			"namespace": Namespace,
+			"annotations": map[string]any{
+				"stream.arcane.sneaksanddata.com/api-group":       "streaming.sneaksanddata.com",
+				"stream.arcane.sneaksanddata.com/api-version":     "v1beta1",
+				"stream.arcane.sneaksanddata.com/api-plural-name": "microsoft-sql-server-streams",
+			},
+		},
+		"status": map[string]any{"active": int64(1)},
+	}}
+}
+
+// NewSecret creates a secret object with the values base64 encoded as the API server returns them.
+func NewSecret(name string, values map[string]string) *unstructured.Unstructured {
+	data := map[string]any{}
+	for key, value := range values {
+		data[key] = base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]any{"name": name, "namespace": Namespace},
+		"data":       data,
+	}}
+}
+
+// NewJobTemplate creates a streaming job template object.
+func NewJobTemplate(name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "streaming.sneaksanddata.com/v1",
+		"kind":       "StreamingJobTemplate",
+		"metadata":   map[string]any{"name": name, "namespace": Namespace},
+		"spec":       map[string]any{"template": map[string]any{}},
+	}}
+}
+
+// NewStream creates a stream object in the phase with the arcane/state annotation, if the state is not empty.
+func NewStream(id string, phase string, state string) *unstructured.Unstructured {
+	stream := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "streaming.sneaksanddata.com/v1beta1",
+		"kind":       StreamKind,
+		"metadata":   map[string]any{"name": id, "namespace": Namespace, "resourceVersion": "1"},
+		"status":     map[string]any{"phase": phase},
+	}}
+	if state != "" {
+		stream.SetAnnotations(map[string]string{models.StateAnnotation: state})
+	}
+	return stream
+}
+
+// UpdateStream changes the stream in the fake cluster. The update is retried if the stream was changed
+// concurrently, e.g. by a patch of the command under test.
+func UpdateStream(t testing.TB, client dynamic.Interface, id string, update func(stream *unstructured.Unstructured)) {
+	resource := client.Resource(StreamSettings.ToGroupVersionResource()).Namespace(Namespace)
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		stream, err := resource.Get(context.Background(), id, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		update(stream)
+		_, err = resource.Update(context.Background(), stream, metav1.UpdateOptions{})
+		return err
+	})
+	assert.NoError(t, err)
+}
+
+// SetPhase changes the phase of the stream in the fake cluster.
+func SetPhase(t testing.TB, client dynamic.Interface, id string, phase string) {
+	UpdateStream(t, client, id, func(stream *unstructured.Unstructured) {
+		assert.NoError(t, unstructured.SetNestedField(stream.Object, phase, "status", "phase"))
+	})
+}
+
+// versionedTracker assigns the resource versions to the objects stored by the fake client. Like in the API server,
+// the versions are taken from a single counter, so the version of a list tells which changes it includes.
+type versionedTracker struct {
+	clienttesting.ObjectTracker
+
+	mu      sync.Mutex
+	version int
+}
+
+func (t *versionedTracker) Create(gvr schema.GroupVersionResource, obj runtime.Object, ns string, opts ...metav1.CreateOptions) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	object, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	object.SetResourceVersion(strconv.Itoa(t.version + 1))
+	if err := t.ObjectTracker.Create(gvr, obj, ns, opts...); err != nil {
+		return err
+	}
+	t.version++
+	return nil
+}
+
+func (t *versionedTracker) Update(gvr schema.GroupVersionResource, obj runtime.Object, ns string, opts ...metav1.UpdateOptions) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.nextVersion(gvr, obj, ns); err != nil {
+		return err
+	}
+	if err := t.ObjectTracker.Update(gvr, obj, ns, opts...); err != nil {
+		return err
+	}
+	t.version++
+	return nil
+}
+
+func (t *versionedTracker) Patch(gvr schema.GroupVersionResource, obj runtime.Object, ns string, opts ...metav1.PatchOptions) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.nextVersion(gvr, obj, ns); err != nil {
+		return err
+	}
+	if err := t.ObjectTracker.Patch(gvr, obj, ns, opts...); err != nil {
+		return err
+	}
+	t.version++
+	return nil
+}
+
+// List implements clienttesting.ObjectTracker, the list has the current resource version.
+func (t *versionedTracker) List(gvr schema.GroupVersionResource, gvk schema.GroupVersionKind, ns string, opts ...metav1.ListOptions) (runtime.Object, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	list, err := t.ObjectTracker.List(gvr, gvk, ns, opts...)
+	if err != nil {
+		return nil, err
+	}
+	listMeta, err := meta.ListAccessor(list)
+	if err != nil {
+		return nil, err
+	}
+	listMeta.SetResourceVersion(strconv.Itoa(t.version))
+	return list, nil
+}
+
+// nextVersion sets the resource version of the changed object to the next version of the tracker.
+// The object must have no resource version or the stored one.
+func (t *versionedTracker) nextVersion(gvr schema.GroupVersionResource, obj runtime.Object, ns string) error {
+	object, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	stored, err := t.Get(gvr, ns, object.GetName())
+	if err != nil {
+		return err
+	}
+	storedObject, err := meta.Accessor(stored)
+	if err != nil {
+		return err
+	}
+	if object.GetResourceVersion() != "" && object.GetResourceVersion() != storedObject.GetResourceVersion() {
+		return apierrors.NewConflict(gvr.GroupResource(), object.GetName(), fmt.Errorf("the object has been modified"))
+	}
+	object.SetResourceVersion(strconv.Itoa(t.version + 1))
+	return nil
+}
+
+// watchFrom starts the watch from the resource version of the watch action. The plain fake client watches
+// only the changes made after the watch started, so the changes made between a list and the following watch
+// of an informer were lost. Here the objects changed after the requested version are sent first.
+func (t *versionedTracker) watchFrom(action clienttesting.Action, listKinds map[schema.GroupVersionResource]string) (bool, watch.Interface, error) {
+	gvr, ns := action.GetResource(), action.GetNamespace()
+	since, err := strconv.Atoi(action.(clienttesting.WatchAction).GetWatchRestrictions().ResourceVersion)
+	if err != nil {
+		// Without a version the watch starts from the current state
+		since = -1
+	}
+
+	t.mu.Lock()
+	source, err := t.ObjectTracker.Watch(gvr, ns)
+	if err != nil {
+		t.mu.Unlock()
+		return true, nil, err
+	}
+	gvk := gvr.GroupVersion().WithKind(strings.TrimSuffix(listKinds[gvr], "List"))
+	list, err := t.ObjectTracker.List(gvr, gvk, ns)
+	t.mu.Unlock()
+	if err != nil {
+		source.Stop()
+		return true, nil, err
+	}
+	objects, err := meta.ExtractList(list)
+	if err != nil {
+		source.Stop()
+		return true, nil, err
+	}
+
+	var missed []watch.Event
+	if since >= 0 {
+		for _, object := range objects {
+			if resourceVersion(object) > since {
+				missed = append(missed, watch.Event{Type: watch.Modified, Object: object})
+			}
+		}
+	}
+	return true, newReplayingWatch(source, missed), nil
+}
+
+// replayingWatch sends the missed events before the events of the source watch. The source events
+// already covered by the missed ones are skipped.
+type replayingWatch struct {
+	source watch.Interface
+	result chan watch.Event
+	stop   chan struct{}
+	once   sync.Once
+}
+
+func newReplayingWatch(source watch.Interface, missed []watch.Event) *replayingWatch {
+	w := &replayingWatch{source: source, result: make(chan watch.Event), stop: make(chan struct{})}
+	go w.run(missed)
+	return w
+}
+
+func (w *replayingWatch) run(missed []watch.Event) {
+	defer close(w.result)
+	sent := map[string]int{}
+	for _, event := range missed {
+		if !w.send(event) {
+			return
+		}
+		sent[objectName(event.Object)] = resourceVersion(event.Object)
+	}
+	for {
+		select {
+		case <-w.stop:
+			return
+		case event, ok := <-w.source.ResultChan():
+			if !ok {
+				return
+			}
+			if event.Type != watch.Deleted && resourceVersion(event.Object) <= sent[objectName(event.Object)] {
+				continue
+			}
+			if !w.send(event) {
+				return
+			}
+		}
+	}
+}
+
+func (w *replayingWatch) send(event watch.Event) bool {
+	select {
+	case w.result <- event:
+		return true
+	case <-w.stop:
+		return false
+	}
+}
+
+func (w *replayingWatch) ResultChan() <-chan watch.Event {
+	return w.result
+}
+
+func (w *replayingWatch) Stop() {
+	w.once.Do(func() {
+		close(w.stop)
+		w.source.Stop()
+	})
+}
+
+func resourceVersion(obj runtime.Object) int {
+	object, err := meta.Accessor(obj)
+	if err != nil {
+		return 0
+	}
+	version, _ := strconv.Atoi(object.GetResourceVersion())
+	return version
+}
+
+func objectName(obj runtime.Object) string {
+	object, err := meta.Accessor(obj)
+	if err != nil {
+		return ""
+	}
+	return object.GetName()
+}
@@ -0,0 +1,83 @@
+package fakes
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// NewStreamDefinition creates the CustomResourceDefinition of the streams in the fake cluster.
+// The schema is a subset of the Microsoft SQL Server stream schema.
+func NewStreamDefinition() *unstructured.Unstructured {
+	templateRef := func(description string) map[string]any {
+		return map[string]any{
+			"type":        "object",
+			"description": description,
+			"required":    []any{"apiGroup", "kind", "name"},
+			"properties": map[string]any{
+				"apiGroup": map[string]any{"type": "string", "default": "streaming.sneaksanddata.com"},
+				"kind":     map[string]any{"type": "string", "default": "StreamingJobTemplate"},
+				"name":     map[string]any{"type": "string", "description": "Name of the job template."},
+			},
+		}
+	}
+	spec := map[string]any{
+		"type":     "object",
+		"required": []any{"connectionStringRef", "sourceSettings", "sinkSettings", "jobTemplateRef", "backfillJobTemplateRef"},
+		"properties": map[string]any{
+			"connectionStringRef": map[string]any{
+				"type":       "object",
+				"required":   []any{"name"},
+				"properties": map[string]any{"name": map[string]any{"type": "string", "description": "Name of the secret with the connection string."}},
+			},
+			"sourceSettings": map[string]any{
+				"type":     "object",
+				"required": []any{"schema", "table", "fetchSize"},
+				"properties": map[string]any{
+					"schema":                       map[string]any{"type": "string"},
+					"table":                        map[string]any{"type": "string"},
+					"fetchSize":                    map[string]any{"type": "integer", "minimum": int64(1), "default": int64(1024)},
+					"changeCaptureIntervalSeconds": map[string]any{"type": "integer", "minimum": int64(1)},
+				},
+			},
+			"sinkSettings": map[string]any{
+				"type":       "object",
+				"required":   []any{"targetTableName"},
+				"properties": map[string]any{"targetTableName": map[string]any{"type": "string", "minLength": int64(1)}},
+			},
+			"jobTemplateRef":         templateRef("The job template of the streaming mode."),
+			"backfillJobTemplateRef": templateRef("The job template of the backfill mode."),
+			"lookBackInterval":       map[string]any{"type": "integer"},
+			"tableProperties": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"format":   map[string]any{"type": "string", "enum": []any{"PARQUET", "ORC", "AVRO"}},
+					"sortedBy": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+				},
+			},
+		},
+	}
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]any{"name": "microsoft-sql-server-streams.streaming.sneaksanddata.com"},
+		"spec": map[string]any{
+			"group": "streaming.sneaksanddata.com",
+			"names": map[string]any{"kind": StreamKind, "plural": "microsoft-sql-server-streams"},
+			"scope": "Namespaced",
+			"versions": []any{map[string]any{
+				"name":    "v1beta1",
+				"served":  true,
+				"storage": true,
+				"schema": map[string]any{"openAPIV3Schema": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"apiVersion": map[string]any{"type": "string"},
+						"kind":       map[string]any{"type": "string"},
+						"metadata":   map[string]any{"type": "object"},
+						"spec":       spec,
+						"status":     map[string]any{"type": "object", "x-kubernetes-preserve-unknown-fields": true},
+					},
+				}},
+			}},
+		},
+	}}
+}
@@ -0,0 +1,108 @@
+package fakes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+)
+
+// Discoverer is an in-memory ApiSettingsDiscoverer recording the discovery calls.
+type Discoverer struct {
+	// Classes maps the stream class names to their API settings.
+	Classes map[string]*models.ClientApiSettings
+
+	// Streams maps the stream IDs to the stream classes having the stream.
+	Streams map[string][]string
+
+	// Jobs maps the IDs of the streams with a job to their stream classes.
+	Jobs map[string]string
+
+	// JobErrors overrides the result of the job discovery for the stream IDs.
+	JobErrors map[string]error
+
+	mu    sync.Mutex
+	calls []string
+}
+
+var _ abstractions.ApiSettingsDiscoverer = (*Discoverer)(nil)
+
+// NewDiscoverer creates a discoverer with the stream class of the fake cluster owning the streams.
+// The streams are running, i.e. they have jobs.
+func NewDiscoverer(ids ...string) *Discoverer {
+	discoverer := &Discoverer{
+		Classes:   map[string]*models.ClientApiSettings{StreamClass: StreamSettings},
+		Streams:   map[string][]string{},
+		Jobs:      map[string]string{},
+		JobErrors: map[string]error{},
+	}
+	for _, id := range ids {
+		discoverer.Streams[id] = []string{StreamClass}
+		discoverer.Jobs[id] = StreamClass
+	}
+	return discoverer
+}
+
+// Calls returns the names of the discovery methods called so far.
+func (d *Discoverer) Calls() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.calls...)
+}
+
+func (d *Discoverer) record(call string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls = append(d.calls, call)
+}
+
+// DiscoveryFromJobs implements abstractions.ApiSettingsDiscoverer.
+func (d *Discoverer) DiscoveryFromJobs(ctx context.Context, jobName string, namespace string) (*models.ClientApiSettings, error) {
+	d.record("DiscoveryFromJobs")
+	if err, ok := d.JobErrors[jobName]; ok {
+		return nil, err
+	}
+	streamClass, ok := d.Jobs[jobName]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", abstractions.ErrJobNotFound, jobName)
+	}
+	return d.Classes[streamClass], nil
+}
+
+// DiscoveryFromStreamClass implements abstractions.ApiSettingsDiscoverer.
+func (d *Discoverer) DiscoveryFromStreamClass(ctx context.Context, streamClass string, namespace string) (*models.ClientApiSettings, error) {
+	d.record("DiscoveryFromStreamClass")
+	settings, ok := d.Classes[streamClass]
+	if !ok {
+		return nil, &abstractions.StreamClassNotFoundError{StreamClass: streamClass, Namespace: namespace}
+	}
+	return settings, nil
+}
+
+// DiscoveryFromStreamId implements abstractions.ApiSettingsDiscoverer.
+func (d *Discoverer) DiscoveryFromStreamId(ctx context.Context, id string, namespace string) (*models.ClientApiSettings, string, error) {
+	d.record("DiscoveryFromStreamId")
+	streamClasses := d.Streams[id]
+	switch len(streamClasses) {
+	case 0:
+		return nil, "", &abstractions.StreamNotFoundError{Id: id, Namespace: namespace}
+	case 1:
+		return d.Classes[streamClasses[0]], streamClasses[0], nil
+	default:
+		return nil, "", &abstractions.AmbiguousStreamError{Id: id, StreamClasses: streamClasses}
+	}
+}
+
+// ListStreamClasses implements abstractions.ApiSettingsDiscoverer.
+func (d *Discoverer) ListStreamClasses(ctx context.Context, namespace string) ([]string, error) {
+	d.record("ListStreamClasses")
+	names := make([]string, 0, len(d.Classes))
+	for name := range d.Classes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,34 @@
+package fakes
+
+import (
+	"testing"
+	"time"
+
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+// PhaseDriver simulates the phase transitions made by the stream operator in the fake cluster.
+type PhaseDriver struct {
+	t        testing.TB
+	client   *dynamicfake.FakeDynamicClient
+	interval time.Duration
+}
+
+// NewPhaseDriver creates a driver changing the phases of the streams with the interval between the changes.
+func NewPhaseDriver(t testing.TB, client *dynamicfake.FakeDynamicClient, interval time.Duration) *PhaseDriver {
+	return &PhaseDriver{t: t, client: client, interval: interval}
+}
+
+// Run moves the stream through the phases in the background, waiting for the interval before each phase.
+// The returned channel is closed after the last phase is set.
+func (d *PhaseDriver) Run(id string, phases ...string) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, phase := range phases {
+			time.Sleep(d.interval)
+			SetPhase(d.t, d.client, id, phase)
+		}
+	}()
+	return done
+}
@@ -0,0 +1,32 @@
+package fakes
+
+import (
+	"context"
+	"fmt"
+
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+)
+
+// JobInspector is an in-memory JobInspector.
+type JobInspector struct {
+	// Jobs maps the job names to their states, the jobs not in the map do not exist.
+	Jobs map[string]*models.JobStatus
+}
+
+var _ abstractions.JobInspector = (*JobInspector)(nil)
+
+// GetJobStatus implements abstractions.JobInspector.
+func (i *JobInspector) GetJobStatus(ctx context.Context, jobName string, namespace string) (*models.JobStatus, error) {
+	return i.Jobs[jobName], nil
+}
+
+// WaitForJobDeletion implements abstractions.JobInspector. The fake jobs are never removed,
+// so it waits for the context if the job exists.
+func (i *JobInspector) WaitForJobDeletion(ctx context.Context, jobName string, namespace string) error {
+	if _, ok := i.Jobs[jobName]; !ok {
+		return nil
+	}
+	<-ctx.Done()
+	return fmt.Errorf("context cancelled while waiting for job %s deletion: %w", jobName, ctx.Err())
+}
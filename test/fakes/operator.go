@@ -0,0 +1,305 @@
+package fakes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"s-vitaliy/kubectl-plugin-arcane/internal/app/abstractions"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Reaction is called by the StreamOperator after a patch changed the arcane/state annotation of the stream.
+// It simulates the stream operator, e.g. by moving the stream to the next phases with SetPhase.
+type Reaction func(operator *StreamOperator, id string, state string)
+
+// StreamOperator is an in-memory StreamClassOperator. The patches change the arcane/state annotation
+// of the stream and the phases are changed by the test or by the Reaction.
+type StreamOperator struct {
+	// Reaction, if set, is called in the background after every applied patch.
+	Reaction Reaction
+
+	mu       sync.Mutex
+	streams  map[string]*streamEntry
+	patches  []string
+	changed  chan struct{}
+	reacting sync.WaitGroup
+}
+
+type streamEntry struct {
+	status  models.StreamStatus
+	version int
+}
+
+var _ abstractions.StreamClassOperator = (*StreamOperator)(nil)
+
+// NewStreamOperator creates an operator managing the streams.
+func NewStreamOperator(streams ...*models.StreamSummary) *StreamOperator {
+	operator := &StreamOperator{streams: map[string]*streamEntry{}, changed: make(chan struct{})}
+	for _, stream := range streams {
+		operator.streams[stream.Name] = &streamEntry{status: models.StreamStatus{StreamSummary: *stream}, version: 1}
+	}
+	return operator
+}
+
+// NewStreamSummary creates the summary of a stream of the fake stream class.
+func NewStreamSummary(id string, phase string, state string) *models.StreamSummary {
+	return &models.StreamSummary{Name: id, Namespace: Namespace, StreamClass: StreamClass, Kind: StreamKind, Phase: phase, State: state}
+}
+
+// Patches returns the patches applied so far in the "<operation> <id>" form.
+func (o *StreamOperator) Patches() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]string(nil), o.patches...)
+}
+
+// SetPhase changes the phase of the stream and notifies the waiters.
+func (o *StreamOperator) SetPhase(id string, phase string) {
+	o.update(id, func(status *models.StreamStatus) { status.Phase = phase })
+}
+
+// CompleteReload removes the reload request and moves the stream to the Running phase, like the operator does
+// when the backfill is over.
+func (o *StreamOperator) CompleteReload(id string) {
+	o.update(id, func(status *models.StreamStatus) {
+		status.State = ""
+		status.Phase = abstractions.StreamPhaseRunning.String()
+	})
+}
+
+// SetFailed moves the stream to the Failed phase with the status message.
+func (o *StreamOperator) SetFailed(id string, message string) {
+	o.update(id, func(status *models.StreamStatus) {
+		status.Phase = abstractions.StreamPhaseFailed.String()
+		status.Message = message
+	})
+}
+
+// WaitReactions waits for the reactions started by the patches to finish.
+func (o *StreamOperator) WaitReactions() {
+	o.reacting.Wait()
+}
+
+func (o *StreamOperator) update(id string, change func(status *models.StreamStatus)) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	entry, ok := o.streams[id]
+	if !ok {
+		panic(fmt.Sprintf("stream %s is not managed by the fake operator", id))
+	}
+	change(&entry.status)
+	entry.version++
+	close(o.changed)
+	o.changed = make(chan struct{})
+	return entry.version
+}
+
+func (o *StreamOperator) patch(ctx context.Context, operation string, id string, state string, dryRun models.DryRunMode) (*models.PatchResult, error) {
+	result := &models.PatchResult{
+		Resource: StreamSettings.ResourceName(),
+		Name:     id,
+		Patch:    fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, models.StateAnnotation, state),
+	}
+	if dryRun.IsDryRun() {
+		result.DryRun = dryRun
+		return result, nil
+	}
+
+	o.mu.Lock()
+	_, ok := o.streams[id]
+	if ok {
+		o.patches = append(o.patches, operation+" "+id)
+	}
+	o.mu.Unlock()
+	if !ok {
+		return nil, &abstractions.StreamNotFoundError{Id: id, Namespace: Namespace}
+	}
+
+	version := o.update(id, func(status *models.StreamStatus) {
+		status.State = state
+		result.State, result.Phase = status.State, status.Phase
+	})
+	result.ResourceVersion = strconv.Itoa(version)
+	if o.Reaction != nil {
+		o.reacting.Add(1)
+		go func() {
+			defer o.reacting.Done()
+			o.Reaction(o, id, state)
+		}()
+	}
+	return result, nil
+}
+
+// Suspend implements abstractions.StreamClassOperator.
+func (o *StreamOperator) Suspend(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings, dryRun models.DryRunMode) (*models.PatchResult, error) {
+	return o.patch(ctx, "suspend", id, "suspended", dryRun)
+}
+
+// Resume implements abstractions.StreamClassOperator.
+func (o *StreamOperator) Resume(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings, dryRun models.DryRunMode) (*models.PatchResult, error) {
+	return o.patch(ctx, "resume", id, "", dryRun)
+}
+
+// Backfill implements abstractions.StreamClassOperator.
+func (o *StreamOperator) Backfill(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings, dryRun models.DryRunMode) (*models.PatchResult, error) {
+	return o.patch(ctx, "backfill", id, "reload-requested", dryRun)
+}
+
+// WaitForStatus implements abstractions.StreamClassOperator with the same semantics as the real operator:
+// the current phase is checked first, the passed transient phases are accepted once the state or the phase
+// changed after the patch, and the wait fails when the stream fails after the patch.
+func (o *StreamOperator) WaitForStatus(ctx context.Context, condition abstractions.WaitCondition, id string, namespace string, apiSettings *models.ClientApiSettings) error {
+	for {
+		o.mu.Lock()
+		entry, ok := o.streams[id]
+		if !ok {
+			o.mu.Unlock()
+			return &abstractions.StreamNotFoundError{Id: id, Namespace: namespace}
+		}
+		status, version, changed := entry.status, entry.version, o.changed
+		o.mu.Unlock()
+
+		observed := condition.Patch == nil || (strconv.Itoa(version) != condition.Patch.ResourceVersion &&
+			(status.State != condition.Patch.State || !strings.EqualFold(status.Phase, condition.Patch.Phase)))
+		if strings.EqualFold(status.Phase, condition.Phase.String()) {
+			return nil
+		}
+		if condition.Patch != nil && observed && containsPhase(condition.PassedPhases, status.Phase) {
+			return nil
+		}
+		if observed && condition.Phase != abstractions.StreamPhaseFailed && !condition.IgnoreFailure && strings.EqualFold(status.Phase, abstractions.StreamPhaseFailed.String()) {
+			return &abstractions.StreamFailedError{Id: id, Phase: status.Phase, Message: status.Message, Conditions: status.Conditions}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("context cancelled while waiting for stream %s status: %w", id, ctx.Err())
+		case <-changed:
+		}
+	}
+}
+
+func containsPhase(phases []abstractions.StreamPhase, phase string) bool {
+	for _, candidate := range phases {
+		if strings.EqualFold(candidate.String(), phase) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetStatus implements abstractions.StreamClassOperator.
+func (o *StreamOperator) GetStatus(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings) (*models.StreamStatus, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	entry, ok := o.streams[id]
+	if !ok {
+		return nil, &abstractions.StreamNotFoundError{Id: id, Namespace: namespace}
+	}
+	status := entry.status
+	return &status, nil
+}
+
+// Patch implements abstractions.StreamClassOperator. The patch is recorded, but the spec of the stream is not kept.
+func (o *StreamOperator) Patch(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings, patch map[string]any, dryRun models.DryRunMode) (*models.PatchResult, error) {
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	result := &models.PatchResult{Resource: StreamSettings.ResourceName(), Name: id, Patch: string(patchBytes)}
+	if dryRun.IsDryRun() {
+		result.DryRun = dryRun
+		return result, nil
+	}
+
+	o.mu.Lock()
+	_, ok := o.streams[id]
+	if ok {
+		o.patches = append(o.patches, "patch "+id)
+	}
+	o.mu.Unlock()
+	if !ok {
+		return nil, &abstractions.StreamNotFoundError{Id: id, Namespace: namespace}
+	}
+	result.ResourceVersion = strconv.Itoa(o.update(id, func(status *models.StreamStatus) {}))
+	return result, nil
+}
+
+// Create implements abstractions.StreamClassOperator. Only the summary of the stream is kept.
+func (o *StreamOperator) Create(ctx context.Context, stream *unstructured.Unstructured, namespace string, apiSettings *models.ClientApiSettings, dryRun models.DryRunMode) (*unstructured.Unstructured, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if _, ok := o.streams[stream.GetName()]; ok {
+		return nil, fmt.Errorf("stream %s already exists", stream.GetName())
+	}
+	if dryRun != models.DryRunServer {
+		summary := models.FromStreamObject(stream)
+		summary.StreamClass = StreamClass
+		o.streams[stream.GetName()] = &streamEntry{status: models.StreamStatus{StreamSummary: *summary}, version: 1}
+		o.patches = append(o.patches, "create "+stream.GetName())
+	}
+	return stream.DeepCopy(), nil
+}
+
+// Delete implements abstractions.StreamClassOperator.
+func (o *StreamOperator) Delete(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings, cascade models.CascadeMode, dryRun models.DryRunMode) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if _, ok := o.streams[id]; !ok {
+		return &abstractions.StreamNotFoundError{Id: id, Namespace: namespace}
+	}
+	if !dryRun.IsDryRun() {
+		delete(o.streams, id)
+		o.patches = append(o.patches, "delete "+id)
+		close(o.changed)
+		o.changed = make(chan struct{})
+	}
+	return nil
+}
+
+// WaitForDeletion implements abstractions.StreamClassOperator.
+func (o *StreamOperator) WaitForDeletion(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings) error {
+	for {
+		o.mu.Lock()
+		_, ok := o.streams[id]
+		changed := o.changed
+		o.mu.Unlock()
+		if !ok {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("context cancelled while waiting for stream %s deletion: %w", id, ctx.Err())
+		case <-changed:
+		}
+	}
+}
+
+// GetStream implements abstractions.StreamClassOperator. The stream has no spec.
+func (o *StreamOperator) GetStream(ctx context.Context, id string, namespace string, apiSettings *models.ClientApiSettings) (*unstructured.Unstructured, error) {
+	status, err := o.GetStatus(ctx, id, namespace, apiSettings)
+	if err != nil {
+		return nil, err
+	}
+	return NewStream(status.Name, status.Phase, status.State), nil
+}
+
+// List implements abstractions.StreamClassOperator. The label selector is ignored.
+func (o *StreamOperator) List(ctx context.Context, namespace string, labelSelector string, apiSettings *models.ClientApiSettings) ([]*models.StreamSummary, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	summaries := make([]*models.StreamSummary, 0, len(o.streams))
+	for _, entry := range o.streams {
+		summary := entry.status.StreamSummary
+		summaries = append(summaries, &summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Name < summaries[j].Name })
+	return summaries, nil
+}
@@ -0,0 +1,70 @@
+package test_models
+
+import (
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestStatusFromStreamObject(t *testing.T) {
+	stream := &unstructured.Unstructured{Object: map[string]any{
+		"kind":     "MicrosoftSqlServerStream",
+		"metadata": map[string]any{"name": "mock-mssql-stream", "namespace": "arcane"},
+		"status": map[string]any{
+			"phase": "Running",
+			"conditions": []any{
+				map[string]any{"type": "Ready", "status": "True", "reason": "JobRunning", "lastTransitionTime": "2025-01-01T00:00:00Z"},
+			},
+		},
+	}}
+
+	status := models.StatusFromStreamObject(stream)
+
+	assert.Equal(t, "Running", status.Phase)
+	assert.Len(t, status.Conditions, 1)
+	assert.Equal(t, "Ready", status.Conditions[0].Type)
+	assert.Equal(t, "True", status.Conditions[0].Status)
+	assert.Equal(t, "JobRunning", status.Conditions[0].Reason)
+	assert.Nil(t, status.Job)
+}
+
+func TestFromPodObjectSumsRestarts(t *testing.T) {
+	pod := &unstructured.Unstructured{Object: map[string]any{
+		"metadata": map[string]any{"name": "mock-mssql-stream-abcde"},
+		"status": map[string]any{
+			"phase":     "Running",
+			"startTime": "2025-01-01T00:00:00Z",
+			"containerStatuses": []any{
+				map[string]any{"restartCount": int64(2)},
+				map[string]any{"restartCount": int64(1)},
+			},
+		},
+	}}
+
+	status := models.FromPodObject(pod)
+
+	assert.Equal(t, "mock-mssql-stream-abcde", status.Name)
+	assert.Equal(t, "Running", status.Phase)
+	assert.Equal(t, int64(3), status.Restarts)
+	assert.Equal(t, "2025-01-01T00:00:00Z", status.StartTime)
+}
+
+func TestFromJobObjectReadsFailureReason(t *testing.T) {
+	job := &unstructured.Unstructured{Object: map[string]any{
+		"metadata": map[string]any{"name": "mock-mssql-stream"},
+		"status": map[string]any{
+			"failed": int64(3),
+			"conditions": []any{
+				map[string]any{"type": "Complete", "status": "False"},
+				map[string]any{"type": "Failed", "status": "True", "reason": "BackoffLimitExceeded", "message": "Job has reached the specified backoff limit"},
+			},
+		},
+	}}
+
+	status := models.FromJobObject(job)
+
+	assert.Equal(t, int64(3), status.Failed)
+	assert.Equal(t, "BackoffLimitExceeded: Job has reached the specified backoff limit", status.FailureReason)
+}
@@ -0,0 +1,42 @@
+package test_models
+
+import (
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestFromStreamObject(t *testing.T) {
+	stream := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "streaming.sneaksanddata.com/v1beta1",
+		"kind":       "MicrosoftSqlServerStream",
+		"metadata": map[string]any{
+			"name":        "mock-mssql-stream",
+			"namespace":   "arcane",
+			"annotations": map[string]any{"arcane/state": "suspended"},
+		},
+		"status": map[string]any{"phase": "Suspended"},
+	}}
+
+	summary := models.FromStreamObject(stream)
+
+	assert.Equal(t, "mock-mssql-stream", summary.Name)
+	assert.Equal(t, "arcane", summary.Namespace)
+	assert.Equal(t, "MicrosoftSqlServerStream", summary.Kind)
+	assert.Equal(t, "Suspended", summary.Phase)
+	assert.Equal(t, "suspended", summary.State)
+}
+
+func TestFromStreamObjectWithoutStatus(t *testing.T) {
+	stream := &unstructured.Unstructured{Object: map[string]any{
+		"kind":     "MicrosoftSqlServerStream",
+		"metadata": map[string]any{"name": "mock-mssql-stream"},
+	}}
+
+	summary := models.FromStreamObject(stream)
+
+	assert.Equal(t, "", summary.Phase)
+	assert.Equal(t, "", summary.State)
+}
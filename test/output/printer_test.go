@@ -0,0 +1,93 @@
+package test_output
+
+import (
+	"bytes"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"s-vitaliy/kubectl-plugin-arcane/internal/output"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var streams = &models.StreamList{Items: []*models.StreamSummary{
+	{Name: "first", Namespace: "arcane", StreamClass: "arcane-stream-microsoft-sql-server", Kind: "MicrosoftSqlServerStream", Phase: "Running"},
+	{Name: "second", Namespace: "arcane", StreamClass: "arcane-stream-microsoft-sql-server", Kind: "MicrosoftSqlServerStream", Phase: "Suspended", State: "suspended"},
+}}
+
+func render(t *testing.T, format string, result any) string {
+	var buffer bytes.Buffer
+	printer, err := output.NewPrinter(format, &buffer)
+	assert.NoError(t, err)
+	assert.NoError(t, printer.Print(result))
+	return buffer.String()
+}
+
+func TestTableOutput(t *testing.T) {
+	text := render(t, "", streams)
+
+	assert.Contains(t, text, "NAME     STREAM CLASS")
+	assert.Contains(t, text, "first    arcane-stream-microsoft-sql-server   MicrosoftSqlServerStream   Running     <none>")
+	assert.Contains(t, text, "second   arcane-stream-microsoft-sql-server   MicrosoftSqlServerStream   Suspended   suspended")
+}
+
+func TestWideOutput(t *testing.T) {
+	text := render(t, "wide", streams)
+
+	assert.Contains(t, text, "NAMESPACE   NAME")
+	assert.Contains(t, text, "arcane      first")
+}
+
+func TestJsonOutput(t *testing.T) {
+	text := render(t, "json", models.NewOperationResult("first", "arcane", "suspended"))
+
+	assert.JSONEq(t, `{"name": "first", "namespace": "arcane", "operation": "suspended"}`, text)
+}
+
+func TestYamlOutput(t *testing.T) {
+	text := render(t, "yaml", models.NewOperationResult("first", "arcane", "suspended"))
+
+	assert.Equal(t, "name: first\nnamespace: arcane\noperation: suspended\n", text)
+}
+
+func TestNameOutput(t *testing.T) {
+	text := render(t, "name", streams)
+
+	assert.Equal(t, "microsoftsqlserverstream/first\nmicrosoftsqlserverstream/second\n", text)
+}
+
+func TestJsonPathOutput(t *testing.T) {
+	assert.Equal(t, "first second\n", render(t, "jsonpath={.items[*].name}", streams))
+	assert.Equal(t, "Suspended\n", render(t, "jsonpath=.items[1].phase", streams))
+}
+
+func TestGoTemplateOutput(t *testing.T) {
+	text := render(t, "go-template={{range .items}}{{.name}}={{.phase}};{{end}}", streams)
+
+	assert.Equal(t, "first=Running;second=Suspended;", text)
+}
+
+func TestUnsupportedOutput(t *testing.T) {
+	_, err := output.NewPrinter("xml", &bytes.Buffer{})
+	assert.Error(t, err)
+
+	_, err = output.NewPrinter("jsonpath=", &bytes.Buffer{})
+	assert.Error(t, err)
+}
+
+func TestDryRunOutput(t *testing.T) {
+	result := models.NewOperationResult("first", "arcane", "suspended")
+	result.Patches = []*models.PatchResult{{
+		DryRun:   models.DryRunClient,
+		Resource: "microsoft-sql-server-streams.v1beta1.streaming.sneaksanddata.com",
+		Name:     "first",
+		Patch:    `{"metadata":{"annotations":{"arcane/state":"suspended"}}}`,
+	}}
+
+	text := render(t, "", result)
+
+	expected := `stream/first suspended (dry run: client)
+PATCH microsoft-sql-server-streams.v1beta1.streaming.sneaksanddata.com/first (application/merge-patch+json)
+{"metadata":{"annotations":{"arcane/state":"suspended"}}}
+`
+	assert.Equal(t, expected, text)
+}
@@ -0,0 +1,82 @@
+package test_output
+
+import (
+	"bytes"
+	"s-vitaliy/kubectl-plugin-arcane/internal/models"
+	"s-vitaliy/kubectl-plugin-arcane/internal/output"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newProgress(phase string) *models.WaitProgress {
+	started := time.Now()
+	return &models.WaitProgress{
+		Name:        "mock-mssql-stream",
+		Namespace:   "arcane",
+		Operation:   "backfill",
+		TargetPhase: "Reloading",
+		Phase:       phase,
+		Started:     started,
+		Deadline:    started.Add(time.Hour),
+		Job: &models.JobStatus{
+			Name:   "mock-mssql-stream",
+			Active: 1,
+			Pods:   []models.PodStatus{{Name: "mock-mssql-stream-abcde", Phase: "Running"}},
+		},
+		LogLines: []string{"[pod/mock-mssql-stream-abcde] reading the change tracking"},
+	}
+}
+
+func TestProgressLineMode(t *testing.T) {
+	var buffer bytes.Buffer
+	view := output.NewProgressView(&buffer, 0)
+
+	view.Update(newProgress("Running"))
+	view.Update(newProgress("Running"))
+	view.Update(newProgress("Reloading"))
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "/1h0m0s] stream/mock-mssql-stream backfill: phase Running, waiting for Reloading, job 1 active / 0 succeeded / 0 failed")
+	assert.Contains(t, lines[1], "phase Reloading")
+	assert.NotContains(t, buffer.String(), "\x1b[")
+}
+
+func TestProgressTerminalMode(t *testing.T) {
+	var buffer bytes.Buffer
+	view := output.NewProgressView(&buffer, 120)
+
+	view.Update(newProgress("Running"))
+	text := buffer.String()
+	assert.Contains(t, text, "stream/mock-mssql-stream backfill: phase Running, waiting for Reloading")
+	assert.Contains(t, text, "  pod/mock-mssql-stream-abcde Running, 0 restarts\n")
+	assert.Contains(t, text, "  [pod/mock-mssql-stream-abcde] reading the change tracking\n")
+	assert.NotContains(t, text, "\x1b[")
+
+	buffer.Reset()
+	view.Update(newProgress("Reloading"))
+	assert.True(t, strings.HasPrefix(buffer.String(), "\x1b[4A\x1b[J"))
+
+	buffer.Reset()
+	_, err := view.Write([]byte("log line\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "\x1b[4A\x1b[Jlog line\n", buffer.String())
+
+	buffer.Reset()
+	view.Clear()
+	assert.Empty(t, buffer.String())
+}
+
+func TestProgressTruncatesLongLines(t *testing.T) {
+	var buffer bytes.Buffer
+	view := output.NewProgressView(&buffer, 20)
+
+	view.Update(newProgress("Running"))
+
+	for _, line := range strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n") {
+		assert.LessOrEqual(t, len([]rune(line)), 19)
+	}
+}
@@ -0,0 +1,144 @@
+package test_schema
+
+import (
+	"s-vitaliy/kubectl-plugin-arcane/internal/schema"
+	"s-vitaliy/kubectl-plugin-arcane/test/fakes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func streamSchema(t *testing.T) map[string]any {
+	versions, _, _ := unstructured.NestedSlice(fakes.NewStreamDefinition().Object, "spec", "versions")
+	openApiSchema, _, err := unstructured.NestedMap(versions[0].(map[string]any), "schema", "openAPIV3Schema")
+	assert.NoError(t, err)
+	return openApiSchema
+}
+
+func specSchema(t *testing.T) map[string]any {
+	spec, err := schema.Lookup(streamSchema(t), "spec")
+	assert.NoError(t, err)
+	return spec
+}
+
+func TestSkeletonKeepsRequiredFieldsAndDefaults(t *testing.T) {
+	skeleton := schema.Skeleton(specSchema(t))
+
+	assert.Equal(t, map[string]any{
+		"connectionStringRef": map[string]any{"name": ""},
+		"sourceSettings":      map[string]any{"schema": "", "table": "", "fetchSize": int64(1024)},
+		"sinkSettings":        map[string]any{"targetTableName": ""},
+		"jobTemplateRef":      map[string]any{"apiGroup": "streaming.sneaksanddata.com", "kind": "StreamingJobTemplate", "name": ""},
+		"backfillJobTemplateRef": map[string]any{
+			"apiGroup": "streaming.sneaksanddata.com", "kind": "StreamingJobTemplate", "name": "",
+		},
+	}, skeleton)
+}
+
+func TestRequiredFieldsSkipDefaults(t *testing.T) {
+	fields := schema.RequiredFields(specSchema(t), "spec")
+
+	paths := make([]string, 0, len(fields))
+	for _, field := range fields {
+		paths = append(paths, field.Path)
+	}
+	assert.Equal(t, []string{
+		"spec.connectionStringRef.name",
+		"spec.sourceSettings.schema",
+		"spec.sourceSettings.table",
+		"spec.sinkSettings.targetTableName",
+		"spec.jobTemplateRef.name",
+		"spec.backfillJobTemplateRef.name",
+	}, paths)
+	assert.Equal(t, "Name of the job template.", fields[4].Description)
+}
+
+func TestLookupAndParseValue(t *testing.T) {
+	fetchSize, err := schema.Lookup(streamSchema(t), "spec.sourceSettings.fetchSize")
+	assert.NoError(t, err)
+	value, err := schema.ParseValue(fetchSize, "2048")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2048), value)
+
+	_, err = schema.ParseValue(fetchSize, "large")
+	assert.EqualError(t, err, `"large" is not an integer`)
+
+	sortedBy, err := schema.Lookup(streamSchema(t), "spec.tableProperties.sortedBy")
+	assert.NoError(t, err)
+	value, err = schema.ParseValue(sortedBy, "[id, created_at]")
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"id", "created_at"}, value)
+
+	item, err := schema.Lookup(streamSchema(t), "spec.tableProperties.sortedBy.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "string", item["type"])
+
+	_, err = schema.Lookup(streamSchema(t), "spec.sourceSettings.unknown")
+	assert.EqualError(t, err, "field spec.sourceSettings.unknown: unknown field unknown")
+}
+
+func TestValidateReportsAllErrors(t *testing.T) {
+	object := map[string]any{
+		"apiVersion": "streaming.sneaksanddata.com/v1beta1",
+		"kind":       fakes.StreamKind,
+		"spec": map[string]any{
+			"connectionStringRef":    map[string]any{"name": "db-credentials"},
+			"sourceSettings":         map[string]any{"schema": "dbo", "table": "users", "fetchSize": int64(0)},
+			"sinkSettings":           map[string]any{"targetTableName": "users_stream"},
+			"jobTemplateRef":         map[string]any{"apiGroup": "streaming.sneaksanddata.com", "kind": "StreamingJobTemplate", "name": "standard-job"},
+			"backfillJobTemplateRef": map[string]any{"apiGroup": "streaming.sneaksanddata.com", "kind": "StreamingJobTemplate", "name": "large-job"},
+			"tableProperties":        map[string]any{"format": "CSV"},
+		},
+	}
+
+	errors, err := schema.Validate(streamSchema(t), object)
+
+	assert.NoError(t, err)
+	assert.Len(t, errors, 2)
+	assert.Contains(t, errors[0], "spec.sourceSettings.fetchSize")
+	assert.Contains(t, errors[1], "spec.tableProperties.format")
+}
+
+func TestValidateAcceptsValidObject(t *testing.T) {
+	skeleton := schema.Skeleton(specSchema(t)).(map[string]any)
+	object := map[string]any{"apiVersion": "streaming.sneaksanddata.com/v1beta1", "kind": fakes.StreamKind, "spec": skeleton}
+	skeleton["sinkSettings"] = map[string]any{"targetTableName": "users_stream"}
+
+	errors, err := schema.Validate(streamSchema(t), object)
+
+	assert.NoError(t, err)
+	assert.Empty(t, errors)
+}
+
+func TestSetValueCreatesParentsAndAppendsItems(t *testing.T) {
+	object := map[string]any{"spec": map[string]any{"tableProperties": map[string]any{"sortedBy": []any{"id"}}}}
+
+	assert.NoError(t, schema.SetValue(object, "spec.tableProperties.sortedBy.1", "updated_at"))
+	assert.NoError(t, schema.SetValue(object, "spec.sourceSettings.fetchSize", int64(5000)))
+	assert.ErrorContains(t, schema.SetValue(object, "spec.tableProperties.sortedBy.5", "name"), "5 is not an index of an array of 2 items")
+	assert.ErrorContains(t, schema.SetValue(object, "spec.sourceSettings.fetchSize.value", int64(1)), "the parent of value is not an object")
+
+	assert.Equal(t, map[string]any{"spec": map[string]any{
+		"tableProperties": map[string]any{"sortedBy": []any{"id", "updated_at"}},
+		"sourceSettings":  map[string]any{"fetchSize": int64(5000)},
+	}}, object)
+}
+
+func TestApplyDefaultsFillsMissingFields(t *testing.T) {
+	object := map[string]any{"spec": map[string]any{
+		"sourceSettings":         map[string]any{"schema": "dbo", "table": "users"},
+		"jobTemplateRef":         map[string]any{"name": "standard-job", "kind": "CustomJobTemplate"},
+		"backfillJobTemplateRef": map[string]any{"name": "large-job"},
+	}}
+
+	schema.ApplyDefaults(streamSchema(t), object)
+
+	assert.Equal(t, map[string]any{"spec": map[string]any{
+		"sourceSettings": map[string]any{"schema": "dbo", "table": "users", "fetchSize": int64(1024)},
+		"jobTemplateRef": map[string]any{"apiGroup": "streaming.sneaksanddata.com", "kind": "CustomJobTemplate", "name": "standard-job"},
+		"backfillJobTemplateRef": map[string]any{
+			"apiGroup": "streaming.sneaksanddata.com", "kind": "StreamingJobTemplate", "name": "large-job",
+		},
+	}}, object)
+}